@@ -0,0 +1,8 @@
+// Package hawk8s embeds the web UI assets, so the binary serves them without
+// depending on the working directory, e.g. when installed as a kubectl plugin.
+package hawk8s
+
+import "embed"
+
+//go:embed static internal/templates/*.html internal/templates/*.svg
+var Assets embed.FS
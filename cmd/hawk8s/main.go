@@ -4,49 +4,84 @@ import (
 	"flag"
 	"fmt"
 	"html/template"
-	"log"
-	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/Masterminds/sprig/v3"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/jawahars16/hawk8s/internal/core"
-	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s"
 )
 
-func main() {
-	port := flag.String("port", "3000", "Port to run the server")
-	verbose := flag.Bool("verbose", false, "Enable verbose logging")
-	flag.Parse()
+type command struct {
+	name        string
+	description string
+	run         func(args []string)
+}
 
-	r := chi.NewRouter()
-	if *verbose {
-		r.Use(middleware.Logger)
-	}
+var commands = []command{
+	{name: "serve", description: "Serve the web UI (default)", run: runServe},
+	{name: "tui", description: "Show the nodes view in the terminal", run: runTUI},
+	{name: "snapshot", description: "Print node and pod usage once and exit", run: runSnapshot},
+}
 
-	kubeclient := kubeclient.NewKubeClient()
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	tmpl := template.Must(template.New("").Funcs(sprig.FuncMap()).ParseGlob("internal/templates/*.html"))
-	coreService := core.NewService(kubeclient)
-	coreHandler := core.NewHandler(tmpl, coreService)
+	for _, c := range commands {
+		if c.name == name {
+			c.run(args)
+			return
+		}
+	}
+	if name == "help" {
+		usage(os.Stdout)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
 
-	r.Get("/", coreHandler.GetIndex)
-	r.Get("/nodes", coreHandler.GetNodes)
-	r.Get("/pods", coreHandler.GetPods)
-	r.Get("/namespaces", coreHandler.GetNamespaces)
+func usage(w *os.File) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", programName())
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.description)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> --help' for the flags of a command.\n", programName())
+}
 
-	fileServer(r)
-	log.Println("Starting server at :3000")
+// programName is how the user invoked hawk8s, "kubectl hawk8s" when it runs as
+// a kubectl plugin.
+func programName() string {
+	name := filepath.Base(os.Args[0])
+	if strings.HasPrefix(name, "kubectl-") {
+		return "kubectl " + strings.TrimPrefix(name, "kubectl-")
+	}
+	return name
+}
 
-	if err := http.ListenAndServe(fmt.Sprintf(":%s", *port), r); err != nil {
-		log.Fatal(err)
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags]\n\nFlags:\n", programName(), name)
+		flags.PrintDefaults()
 	}
+	return flags
+}
+
+func parseTemplates() *template.Template {
+	return template.Must(template.New("").Funcs(sprig.FuncMap()).ParseFS(hawk8s.Assets, "internal/templates/*.html", "internal/templates/*.svg"))
 }
 
-func fileServer(r chi.Router) {
-	fs := http.StripPrefix("/static", http.FileServer(http.Dir("./static")))
-	r.Get("/static/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Println(r.URL.Path)
-		fs.ServeHTTP(w, r)
-	}))
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
 }
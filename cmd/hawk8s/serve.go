@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jawahars16/hawk8s"
+	"github.com/jawahars16/hawk8s/internal/alerting"
+	"github.com/jawahars16/hawk8s/internal/auth"
+	"github.com/jawahars16/hawk8s/internal/cache"
+	"github.com/jawahars16/hawk8s/internal/certs"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+)
+
+func runServe(args []string) {
+	flags := newFlagSet("serve")
+	port := flags.String("port", "3000", "Port to run the server")
+	kubeconfig := flags.String("kubeconfig", kubeclient.DefaultKubeconfig(), "(optional) absolute path to the kubeconfig file")
+	verbose := flags.Bool("verbose", false, "Enable verbose logging")
+	tokenFile := flags.String("auth-token-file", "", "CSV file of static bearer tokens (token,user[,uid[,groups]])")
+	htpasswdFile := flags.String("auth-htpasswd-file", "", "htpasswd file of basic auth users (bcrypt or SHA)")
+	oidcIssuer := flags.String("oidc-issuer-url", "", "OIDC issuer URL, enables login with an OIDC provider")
+	oidcClientID := flags.String("oidc-client-id", "", "OIDC client ID")
+	oidcClientSecret := flags.String("oidc-client-secret", os.Getenv("HAWK8S_OIDC_CLIENT_SECRET"), "OIDC client secret (defaults to $HAWK8S_OIDC_CLIENT_SECRET)")
+	oidcRedirectURL := flags.String("oidc-redirect-url", "", "OIDC redirect URL, e.g. https://hawk8s.example.com/auth/callback")
+	oidcUsernameClaim := flags.String("oidc-username-claim", "email", "ID token claim used as the user name")
+	oidcGroupsClaim := flags.String("oidc-groups-claim", "groups", "ID token claim used as the user groups")
+	audit := flags.Bool("audit-log", false, "Log the authenticated user of every request")
+	authz := flags.Bool("authz", false, "Only show users the namespaces and nodes they may list, checked with SubjectAccessReviews")
+	authzAdminUsers := flags.String("authz-admin-users", "", "Comma separated users that see the whole cluster")
+	authzAdminGroups := flags.String("authz-admin-groups", "", "Comma separated groups that see the whole cluster")
+	authzCacheSeconds := flags.Int("authz-cache-seconds", 60, "How long access review results are cached")
+	tlsCert := flags.String("tls-cert", "", "TLS certificate file, reloaded when it changes")
+	tlsKey := flags.String("tls-key", "", "TLS private key file, reloaded when it changes")
+	tlsClientCA := flags.String("tls-client-ca", "", "CA bundle to verify client certificates against (mutual TLS)")
+	tlsClientAuth := flags.String("tls-client-auth", certs.RequireClientCert, "Client certificate policy with --tls-client-ca: require or verify-if-given")
+	tlsSelfSigned := flags.Bool("tls-self-signed", false, "Serve TLS with a generated self-signed certificate")
+	tlsHosts := flags.String("tls-self-signed-hosts", "localhost,127.0.0.1,::1", "Comma separated host names and IPs of the self-signed certificate")
+	pricingFile := flags.String("pricing-file", "", "YAML file of node prices per vCPU-hour and GiB-hour, enables the cost view")
+	historyRetention := flags.Duration("history-retention", 24*time.Hour, "How long usage samples are kept, 0 to disable the history")
+	historyInterval := flags.Duration("history-interval", time.Minute, "How often usage samples are kept")
+	historyPodSamples := flags.Int("history-max-pod-samples", 500000, "Most pod samples kept in the history, which is thinned to a coarser interval beyond, 0 for no limit")
+	rightsizingWindow := flags.Duration("rightsizing-window", core.DefaultRightsizingOptions.Window, "Default usage window of the rightsizing recommendations")
+	rightsizingPercentile := flags.Float64("rightsizing-cpu-percentile", core.DefaultRightsizingOptions.CPUPercentile, "Percentile of the CPU usage recommended as CPU request")
+	rightsizingHeadroom := flags.Float64("rightsizing-headroom", core.DefaultRightsizingOptions.Headroom, "Fraction added to the peak usage for memory requests and limits")
+	alertRules := flags.String("alert-rules", "", "YAML file of alerting rules and webhook receivers, enables alerting")
+	alertInterval := flags.Duration("alert-interval", 30*time.Second, "How often the alerting rules are evaluated")
+	flags.Parse(args)
+
+	tlsConfig, err := certs.NewConfig(context.Background(), certs.Options{
+		CertFile:     *tlsCert,
+		KeyFile:      *tlsKey,
+		ClientCAFile: *tlsClientCA,
+		ClientAuth:   *tlsClientAuth,
+		SelfSigned:   *tlsSelfSigned,
+		Hosts:        splitList(*tlsHosts),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	if *verbose {
+		r.Use(middleware.Logger)
+	}
+
+	kubeClient := kubeclient.NewKubeClient(*kubeconfig)
+	kubeClient.KeepHistory(*historyRetention, *historyInterval, *historyPodSamples)
+	kubeClient.Run(context.Background())
+
+	tmpl := parseTemplates()
+	var serviceOptions []core.Option
+	if *authz {
+		reviewer := kubeclient.NewAccessReviewer(kubeClient.Clientset(), cache.New(*authzCacheSeconds))
+		serviceOptions = append(serviceOptions, core.WithAuthorization(reviewer, splitList(*authzAdminUsers), splitList(*authzAdminGroups)))
+	}
+	if *pricingFile != "" {
+		pricing, err := core.LoadPricing(*pricingFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		serviceOptions = append(serviceOptions, core.WithPricing(pricing))
+	}
+	if *alertRules != "" {
+		config, err := alerting.LoadConfig(*alertRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+		engine := alerting.NewEngine(*config, kubeClient)
+		go engine.Run(context.Background(), *alertInterval)
+		serviceOptions = append(serviceOptions, core.WithAlerting(engine))
+	}
+	serviceOptions = append(serviceOptions, core.WithRightsizing(core.RightsizingOptions{
+		Window:        *rightsizingWindow,
+		CPUPercentile: *rightsizingPercentile,
+		Headroom:      *rightsizingHeadroom,
+	}))
+	coreService := core.NewService(kubeClient, serviceOptions...)
+	coreHandler := core.NewHandler(tmpl, coreService)
+	exporter := core.NewExporter(tmpl, coreService)
+
+	var authenticators []auth.Authenticator
+	if *tlsClientCA != "" {
+		authenticators = append(authenticators, auth.NewClientCertAuthenticator())
+	}
+	if *tokenFile != "" {
+		tokens, err := auth.NewTokenAuthenticator(*tokenFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		authenticators = append(authenticators, tokens)
+	}
+	if *htpasswdFile != "" {
+		htpasswd, err := auth.NewHtpasswdAuthenticator(*htpasswdFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		authenticators = append(authenticators, htpasswd)
+	}
+	if *oidcIssuer != "" {
+		oidc, err := auth.NewOIDC(context.Background(), auth.OIDCConfig{
+			IssuerURL:     *oidcIssuer,
+			ClientID:      *oidcClientID,
+			ClientSecret:  *oidcClientSecret,
+			RedirectURL:   *oidcRedirectURL,
+			UsernameClaim: *oidcUsernameClaim,
+			GroupsClaim:   *oidcGroupsClaim,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		authenticators = append(authenticators, oidc)
+		r.Get("/auth/login", oidc.Login)
+		r.Get("/auth/callback", oidc.Callback)
+		r.Get("/auth/logout", oidc.Logout)
+	}
+
+	if *authz && len(authenticators) == 0 {
+		log.Fatal("--authz requires an authentication method")
+	}
+
+	r.Group(func(r chi.Router) {
+		if len(authenticators) > 0 {
+			r.Use(auth.Middleware(authenticators...))
+			if *audit {
+				r.Use(auth.Audit)
+			}
+		}
+
+		r.Get("/", coreHandler.GetIndex)
+		r.Get("/nodes", coreHandler.GetNodes)
+		r.Get("/pods", coreHandler.GetPods)
+		r.Get("/namespaces", coreHandler.GetNamespaces)
+		r.Get("/treemap", coreHandler.GetTreemap)
+		r.Get("/heatmap", coreHandler.GetHeatmap)
+		r.Get("/storage", coreHandler.GetStorage)
+		r.Get("/devices", coreHandler.GetDevices)
+		r.Get("/costs", coreHandler.GetCosts)
+		r.Get("/recommendations", coreHandler.GetRecommendations)
+		r.Get("/risks", coreHandler.GetRisks)
+		r.Get("/drain", coreHandler.GetDrain)
+		r.Get("/reports/consolidation", coreHandler.GetConsolidation)
+		r.Get("/events", coreHandler.GetEvents)
+		r.Get("/troubled", coreHandler.GetTroubledPods)
+		r.Get("/alerts", coreHandler.GetAlerts)
+		r.Get("/export", exporter.GetExport)
+		r.Get("/api/v1/nodes.json", coreHandler.GetNodesJSON)
+		r.Get("/api/v1/heatmap.json", coreHandler.GetHeatmapJSON)
+		r.Get("/api/v1/export.csv", coreHandler.GetUsageCSV)
+		r.Get("/api/v1/export.xlsx", coreHandler.GetUsageXLSX)
+		r.Get("/api/v1/recommendations.json", coreHandler.GetRecommendationsJSON)
+		r.Get("/api/v1/recommendations/patches", coreHandler.GetPatches)
+	})
+
+	fileServer(r)
+
+	server := &http.Server{
+		Addr:      fmt.Sprintf(":%s", *port),
+		Handler:   r,
+		TLSConfig: tlsConfig,
+	}
+	if tlsConfig != nil {
+		log.Printf("Starting server at https://localhost:%s", *port)
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		log.Printf("Starting server at http://localhost:%s", *port)
+		err = server.ListenAndServe()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fileServer(r chi.Router) {
+	static, err := fs.Sub(hawk8s.Assets, "static")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fileServer := http.StripPrefix("/static", http.FileServer(http.FS(static)))
+	r.Get("/static/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Println(r.URL.Path)
+		fileServer.ServeHTTP(w, r)
+	}))
+}
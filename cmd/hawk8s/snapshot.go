@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+)
+
+func runSnapshot(args []string) {
+	flags := newFlagSet("snapshot")
+	kubeconfig := flags.String("kubeconfig", kubeclient.DefaultKubeconfig(), "(optional) absolute path to the kubeconfig file")
+	output := flags.String("o", core.Table, "Output format: table, json, yaml, or svg and html to export the nodes view")
+	namespace := flags.String("namespace", "all", "Only include pods of this namespace, or highlight them with svg and html")
+	mode := flags.String("mode", core.CPU, "Mode of the svg and html export: cpu or memory")
+	flags.Parse(args)
+
+	ctx := context.Background()
+	kubeClient := kubeclient.NewKubeClient(*kubeconfig)
+	if err := kubeClient.Load(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	coreService := core.NewService(kubeClient)
+	var err error
+	if *output == core.SVG || *output == core.HTML {
+		err = core.NewExporter(parseTemplates(), coreService).Write(ctx, os.Stdout, *output, *mode, *namespace)
+	} else {
+		err = coreService.WriteSnapshot(ctx, os.Stdout, *output, *namespace)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/tui"
+)
+
+func runTUI(args []string) {
+	flags := newFlagSet("tui")
+	kubeconfig := flags.String("kubeconfig", kubeclient.DefaultKubeconfig(), "(optional) absolute path to the kubeconfig file")
+	mode := flags.String("mode", core.CPU, "Initial mode: cpu or memory")
+	namespace := flags.String("namespace", "all", "Initial namespace to highlight")
+	flags.Parse(args)
+
+	kubeClient := kubeclient.NewKubeClient(*kubeconfig)
+	kubeClient.Run(context.Background())
+	coreService := core.NewService(kubeClient)
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := screen.Init(); err != nil {
+		log.Fatal(err)
+	}
+	defer screen.Fini()
+
+	if err := tui.NewTUI(coreService, screen, *mode, *namespace).Run(context.Background()); err != nil {
+		screen.Fini()
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,264 @@
+package alerting_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/alerting"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// receiver records the notifications posted to it, by path.
+type receiver struct {
+	server   *httptest.Server
+	received map[string][]map[string]interface{}
+	lock     sync.Mutex
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{received: make(map[string][]map[string]interface{})}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var payload map[string]interface{}
+		assert.Nil(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		r.lock.Lock()
+		r.received[req.URL.Path] = append(r.received[req.URL.Path], payload)
+		r.lock.Unlock()
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) get(path string) []map[string]interface{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.received[path]
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func Test_LoadConfig(t *testing.T) {
+	t.Run("given a rules file, then load the rules with their defaults", func(t *testing.T) {
+		config, err := alerting.LoadConfig(writeConfig(t, `
+rules:
+- name: node-cpu
+  metric: node_cpu_allocation
+  threshold: "0.9"
+  for: 10m
+- name: restarts
+  metric: pod_restarts
+  threshold: 3
+  receivers: [ops]
+receivers:
+- name: ops
+  url: http://example.com/hook
+`))
+		assert.Nil(t, err)
+		assert.Equal(t, 10*time.Minute, config.Rules[0].For.Duration)
+		assert.Equal(t, 0.9, config.Rules[0].Threshold.AsApproximateFloat64())
+		assert.Equal(t, 15*time.Minute, config.Rules[1].Window.Duration)
+		assert.Equal(t, alerting.Webhook, config.Receivers[0].Type)
+	})
+
+	t.Run("given an unknown metric, then return an error", func(t *testing.T) {
+		_, err := alerting.LoadConfig(writeConfig(t, `
+rules:
+- name: typo
+  metric: node_cpu
+  threshold: 1
+`))
+		assert.ErrorContains(t, err, `rule typo: unknown metric "node_cpu"`)
+	})
+
+	t.Run("given an unknown receiver, then return an error", func(t *testing.T) {
+		_, err := alerting.LoadConfig(writeConfig(t, `
+rules:
+- name: pending
+  metric: pending_pods
+  threshold: 1
+  receivers: [pager]
+`))
+		assert.ErrorContains(t, err, `rule pending: unknown receiver "pager"`)
+	})
+}
+
+func Test_Engine(t *testing.T) {
+	t.Run("given a node above the threshold for the duration, then fire and resolve to every receiver", func(t *testing.T) {
+		hooks := newReceiver(t)
+		pods := []kubeclient.Pod{{Name: "web", Namespace: "shop", Node: "node1", Phase: "Running", Workload: "Deployment/web", CPURequest: 950}}
+		var lock sync.Mutex
+		source := &alerting.SourceMock{
+			GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+				return []kubeclient.Node{{Name: "node1", AvailableCPU: 1000}, {Name: "node2", AvailableCPU: 1000}}, nil
+			},
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				return pods, nil
+			},
+		}
+		config := alerting.Config{
+			Rules: []alerting.Rule{{
+				Name: "node-cpu", Metric: alerting.NodeCPUAllocation, Severity: "critical",
+				Threshold: resource.MustParse("0.9"), For: alerting.Duration{Duration: 10 * time.Minute},
+			}},
+			Receivers: []alerting.Receiver{
+				{Name: "hook", Type: alerting.Webhook, URL: hooks.server.URL + "/hook"},
+				{Name: "slack", Type: alerting.Slack, URL: hooks.server.URL + "/slack"},
+				{Name: "teams", Type: alerting.Teams, URL: hooks.server.URL + "/teams"},
+			},
+		}
+		engine := alerting.NewEngine(config, source)
+		start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		assert.Nil(t, engine.Evaluate(context.Background(), start))
+		status, err := engine.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(status[0].Alerts))
+		assert.Equal(t, alerting.Pending, status[0].Alerts[0].State)
+		assert.Equal(t, map[string]string{"node": "node1"}, status[0].Alerts[0].Labels)
+		assert.Empty(t, hooks.get("/hook"))
+
+		assert.Nil(t, engine.Evaluate(context.Background(), start.Add(10*time.Minute)))
+		status, _ = engine.Status()
+		assert.Equal(t, alerting.Firing, status[0].Alerts[0].State)
+		fired := hooks.get("/hook")
+		assert.Equal(t, 1, len(fired))
+		assert.Equal(t, "firing", fired[0]["status"])
+		assert.Equal(t, "node-cpu", fired[0]["rule"])
+		assert.Equal(t, "critical", fired[0]["severity"])
+		assert.Equal(t, "node_cpu_allocation of node=node1 is 95%, above 90%", fired[0]["summary"])
+		assert.Equal(t, "[FIRING] node-cpu: node_cpu_allocation of node=node1 is 95%, above 90%", hooks.get("/slack")[0]["text"])
+		assert.Equal(t, "MessageCard", hooks.get("/teams")[0]["@type"])
+
+		assert.Nil(t, engine.Evaluate(context.Background(), start.Add(11*time.Minute)))
+		assert.Equal(t, 1, len(hooks.get("/hook")))
+
+		lock.Lock()
+		pods = nil
+		lock.Unlock()
+		assert.Nil(t, engine.Evaluate(context.Background(), start.Add(12*time.Minute)))
+		status, _ = engine.Status()
+		assert.Equal(t, alerting.Resolved, status[0].Alerts[0].State)
+		resolved := hooks.get("/hook")
+		assert.Equal(t, 2, len(resolved))
+		assert.Equal(t, "resolved", resolved[1]["status"])
+		assert.Equal(t, "node_cpu_allocation of node=node1 is back below 90%", resolved[1]["summary"])
+		assert.Equal(t, "388E3C", hooks.get("/teams")[1]["themeColor"])
+
+		assert.Nil(t, engine.Evaluate(context.Background(), start.Add(2*time.Hour)))
+		status, _ = engine.Status()
+		assert.Empty(t, status[0].Alerts)
+	})
+
+	t.Run("given a condition that clears before the duration, then do not notify", func(t *testing.T) {
+		hooks := newReceiver(t)
+		pending := []kubeclient.Pod{{Name: "big", Namespace: "batch", Phase: "Pending"}}
+		source := &alerting.SourceMock{
+			GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) { return nil, nil },
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				return pending, nil
+			},
+		}
+		engine := alerting.NewEngine(alerting.Config{
+			Rules:     []alerting.Rule{{Name: "pending", Metric: alerting.PendingPods, For: alerting.Duration{Duration: 5 * time.Minute}}},
+			Receivers: []alerting.Receiver{{Name: "hook", URL: hooks.server.URL}},
+		}, source)
+		start := time.Now()
+
+		assert.Nil(t, engine.Evaluate(context.Background(), start))
+		pending = nil
+		assert.Nil(t, engine.Evaluate(context.Background(), start.Add(time.Minute)))
+		status, _ := engine.Status()
+		assert.Empty(t, status[0].Alerts)
+		assert.Empty(t, hooks.get("/"))
+	})
+
+	t.Run("given pods restarting within the window, then fire per pod", func(t *testing.T) {
+		hooks := newReceiver(t)
+		source := &alerting.SourceMock{
+			GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) { return nil, nil },
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				return []kubeclient.Pod{
+					{Name: "api", Namespace: "shop", Restarts: 9},
+					{Name: "web", Namespace: "shop", Restarts: 20},
+					{Name: "new", Namespace: "shop", Restarts: 5},
+				}, nil
+			},
+			GetHistoryFunc: func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+				return []kubeclient.Sample{{Pods: []kubeclient.PodSample{
+					{Name: "api", Namespace: "shop", Restarts: 2},
+					{Name: "web", Namespace: "shop", Restarts: 19},
+				}}}, nil
+			},
+		}
+		engine := alerting.NewEngine(alerting.Config{
+			Rules: []alerting.Rule{{
+				Name: "restarts", Metric: alerting.PodRestarts, Threshold: resource.MustParse("3"),
+				Window: alerting.Duration{Duration: 15 * time.Minute},
+			}},
+			Receivers: []alerting.Receiver{{Name: "hook", Type: alerting.Webhook, URL: hooks.server.URL + "/hook"}},
+		}, source)
+
+		now := time.Now()
+		assert.Nil(t, engine.Evaluate(context.Background(), now))
+		fired := hooks.get("/hook")
+		assert.Equal(t, 1, len(fired))
+		assert.Equal(t, map[string]interface{}{"namespace": "shop", "pod": "api"}, fired[0]["labels"])
+		assert.Equal(t, 7.0, fired[0]["value"])
+		assert.Equal(t, now.Add(-15*time.Minute), source.GetHistoryCalls()[0].Since)
+	})
+
+	t.Run("given a failing receiver, then keep the error on the alert and retry it alone", func(t *testing.T) {
+		failing, calls := true, 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if failing {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer server.Close()
+		other := newReceiver(t)
+		source := &alerting.SourceMock{
+			GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) { return nil, nil },
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				return []kubeclient.Pod{{Name: "big", Namespace: "batch", Phase: "Pending"}}, nil
+			},
+		}
+		engine := alerting.NewEngine(alerting.Config{
+			Rules:     []alerting.Rule{{Name: "pending", Metric: alerting.PendingPods, Namespace: "batch"}},
+			Receivers: []alerting.Receiver{{Name: "hook", URL: server.URL}, {Name: "other", URL: other.server.URL + "/other"}},
+		}, source)
+
+		assert.Nil(t, engine.Evaluate(context.Background(), time.Now()))
+		status, _ := engine.Status()
+		assert.Equal(t, alerting.Firing, status[0].Alerts[0].State)
+		assert.Contains(t, status[0].Alerts[0].Error, "502 Bad Gateway")
+		assert.Equal(t, 1, len(other.get("/other")))
+
+		failing = false
+		assert.Nil(t, engine.Evaluate(context.Background(), time.Now()))
+		status, _ = engine.Status()
+		assert.Equal(t, "", status[0].Alerts[0].Error)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 1, len(other.get("/other")))
+
+		assert.Nil(t, engine.Evaluate(context.Background(), time.Now()))
+		assert.Equal(t, 2, calls)
+	})
+}
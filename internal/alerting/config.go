@@ -0,0 +1,139 @@
+// Package alerting evaluates user defined rules against the nodes and pods in
+// the store and notifies webhooks when alerts fire and resolve.
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// Metrics the rules can alert on. Usage is in bytes and cores, allocation is
+// the ratio of the requests to the allocatable resources of a node.
+const (
+	NamespaceMemoryUsage = "namespace_memory_usage"
+	NamespaceCPUUsage    = "namespace_cpu_usage"
+	NodeCPUAllocation    = "node_cpu_allocation"
+	NodeMemoryAllocation = "node_memory_allocation"
+	PodRestarts          = "pod_restarts"
+	PendingPods          = "pending_pods"
+)
+
+// Receiver types.
+const (
+	Webhook = "webhook"
+	Slack   = "slack"
+	Teams   = "teams"
+)
+
+const defaultRestartWindow = 15 * time.Minute
+
+type Config struct {
+	Rules     []Rule     `json:"rules"`
+	Receivers []Receiver `json:"receivers"`
+}
+
+// Rule fires when the metric is above the threshold for at least For. A rule
+// on a namespace or node metric has an alert per namespace or node, unless
+// Namespace or Node limits it to one.
+type Rule struct {
+	Name      string            `json:"name"`
+	Metric    string            `json:"metric"`
+	Threshold resource.Quantity `json:"threshold"`
+	For       Duration          `json:"for,omitempty"`
+	// Window is how far back pod restarts are counted, 15 minutes by default.
+	Window    Duration `json:"window,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Node      string   `json:"node,omitempty"`
+	Severity  string   `json:"severity,omitempty"`
+	// Receivers names the receivers notified, all of them when empty.
+	Receivers []string `json:"receivers,omitempty"`
+}
+
+type Receiver struct {
+	Name string `json:"name"`
+	// Type is webhook, slack or teams, webhook by default.
+	Type    string            `json:"type,omitempty"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Duration reads durations like 10m from the rules file.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func (c *Config) validate() error {
+	receivers := make(map[string]bool)
+	for i, r := range c.Receivers {
+		if r.Name == "" || r.URL == "" {
+			return fmt.Errorf("receiver %d needs a name and a url", i)
+		}
+		switch r.Type {
+		case "":
+			c.Receivers[i].Type = Webhook
+		case Webhook, Slack, Teams:
+		default:
+			return fmt.Errorf("receiver %s: unknown type %q", r.Name, r.Type)
+		}
+		receivers[r.Name] = true
+	}
+	names := make(map[string]bool)
+	for i, r := range c.Rules {
+		if r.Name == "" || names[r.Name] {
+			return fmt.Errorf("rule %d needs a unique name", i)
+		}
+		names[r.Name] = true
+		switch r.Metric {
+		case NamespaceMemoryUsage, NamespaceCPUUsage, NodeCPUAllocation, NodeMemoryAllocation, PendingPods:
+		case PodRestarts:
+			if r.Window.Duration == 0 {
+				c.Rules[i].Window.Duration = defaultRestartWindow
+			}
+		default:
+			return fmt.Errorf("rule %s: unknown metric %q", r.Name, r.Metric)
+		}
+		for _, receiver := range r.Receivers {
+			if !receivers[receiver] {
+				return fmt.Errorf("rule %s: unknown receiver %q", r.Name, receiver)
+			}
+		}
+	}
+	return nil
+}
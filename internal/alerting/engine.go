@@ -0,0 +1,243 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alert states.
+const (
+	Pending  = "pending"
+	Firing   = "firing"
+	Resolved = "resolved"
+)
+
+// resolvedRetention is how long resolved alerts stay visible.
+const resolvedRetention = time.Hour
+
+// Alert is the state of a rule for one namespace, node or pod.
+type Alert struct {
+	Rule      string
+	Metric    string
+	Severity  string
+	Labels    map[string]string
+	Value     float64
+	Threshold float64
+	State     string
+	// ActiveAt is when the metric went above the threshold, ResolvedAt when
+	// it went back below after firing.
+	ActiveAt   time.Time
+	ResolvedAt time.Time
+	// Error is the last notification error.
+	Error string
+	// failed are the receivers the last notification did not reach, which
+	// are notified again on the next evaluation.
+	failed []string
+}
+
+// RuleStatus is a rule with its current alerts.
+type RuleStatus struct {
+	Rule   Rule
+	Alerts []Alert
+}
+
+type Engine struct {
+	config Config
+	source Source
+	client *http.Client
+	alerts map[string]*Alert
+	// err is the error of the last evaluation.
+	err  error
+	lock sync.RWMutex
+}
+
+func NewEngine(config Config, source Source) *Engine {
+	return &Engine{
+		config: config,
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+		alerts: make(map[string]*Alert),
+	}
+}
+
+// Run evaluates the rules every interval until ctx is done.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Evaluate(ctx, time.Now()); err != nil {
+			log.Printf("Evaluating alerting rules: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate moves the alerts of every rule between pending, firing and
+// resolved, and sends the notifications of the alerts that fired or resolved,
+// retrying the ones that failed.
+func (e *Engine) Evaluate(ctx context.Context, now time.Time) error {
+	nodes, err := e.source.GetNodes(ctx)
+	if err != nil {
+		return e.fail(err)
+	}
+	pods, err := e.source.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return e.fail(err)
+	}
+	snap := snapshot{nodes: nodes, pods: pods}
+
+	var notifications []*Alert
+	// receivers of each notification, nil for all of the rule's receivers.
+	var receivers [][]string
+	e.lock.Lock()
+	e.err = nil
+	for _, rule := range e.config.Rules {
+		measured, err := e.measure(ctx, rule, snap, now)
+		if err != nil {
+			e.err = err
+			continue
+		}
+		threshold := rule.Threshold.AsApproximateFloat64()
+		active := make(map[string]bool)
+		for _, s := range measured {
+			if s.value <= threshold {
+				continue
+			}
+			key := alertKey(rule.Name, s.labels)
+			active[key] = true
+			alert, ok := e.alerts[key]
+			if !ok || alert.State == Resolved {
+				alert = &Alert{
+					Rule:     rule.Name,
+					Metric:   rule.Metric,
+					Severity: rule.Severity,
+					Labels:   s.labels,
+					State:    Pending,
+					ActiveAt: now,
+				}
+				e.alerts[key] = alert
+			}
+			alert.Value = s.value
+			alert.Threshold = threshold
+			if alert.State == Pending && now.Sub(alert.ActiveAt) >= rule.For.Duration {
+				alert.State = Firing
+				notifications = append(notifications, alert)
+				receivers = append(receivers, nil)
+			}
+		}
+		for key, alert := range e.alerts {
+			if alert.Rule != rule.Name || active[key] {
+				continue
+			}
+			switch {
+			case alert.State == Pending:
+				delete(e.alerts, key)
+			case alert.State == Firing:
+				alert.State = Resolved
+				alert.ResolvedAt = now
+				notifications = append(notifications, alert)
+				receivers = append(receivers, nil)
+			case now.Sub(alert.ResolvedAt) > resolvedRetention:
+				delete(e.alerts, key)
+			}
+		}
+	}
+	// Notifications that did not reach every receiver are sent again to the
+	// ones they missed, unless the alert changed state and is notified anew.
+	queued := make(map[*Alert]bool)
+	for _, alert := range notifications {
+		queued[alert] = true
+	}
+	var retries []*Alert
+	for _, alert := range e.alerts {
+		if len(alert.failed) > 0 && !queued[alert] {
+			retries = append(retries, alert)
+		}
+	}
+	sort.Slice(retries, func(i, j int) bool {
+		return alertKey(retries[i].Rule, retries[i].Labels) < alertKey(retries[j].Rule, retries[j].Labels)
+	})
+	for _, alert := range retries {
+		notifications = append(notifications, alert)
+		receivers = append(receivers, alert.failed)
+	}
+	// Copies are sent, as the next evaluation may change the alerts.
+	sent := make([]Alert, len(notifications))
+	for i, alert := range notifications {
+		sent[i] = *alert
+	}
+	e.lock.Unlock()
+
+	for i, alert := range sent {
+		errMessage := ""
+		failed, err := e.notify(ctx, alert, receivers[i])
+		if err != nil {
+			log.Printf("Notifying alert %s: %v", alert.Rule, err)
+			errMessage = err.Error()
+		}
+		e.lock.Lock()
+		notifications[i].Error = errMessage
+		notifications[i].failed = failed
+		e.lock.Unlock()
+	}
+	return nil
+}
+
+func (e *Engine) fail(err error) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.err = err
+	return err
+}
+
+// Status returns every rule with its alerts, firing first.
+func (e *Engine) Status() ([]RuleStatus, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	result := make([]RuleStatus, 0, len(e.config.Rules))
+	for _, rule := range e.config.Rules {
+		status := RuleStatus{Rule: rule}
+		for _, alert := range e.alerts {
+			if alert.Rule == rule.Name {
+				status.Alerts = append(status.Alerts, *alert)
+			}
+		}
+		sort.Slice(status.Alerts, func(i, j int) bool {
+			a, b := status.Alerts[i], status.Alerts[j]
+			if a.State != b.State {
+				return stateOrder[a.State] < stateOrder[b.State]
+			}
+			return alertKey("", a.Labels) < alertKey("", b.Labels)
+		})
+		result = append(result, status)
+	}
+	return result, e.err
+}
+
+var stateOrder = map[string]int{Firing: 0, Pending: 1, Resolved: 2}
+
+func alertKey(rule string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(rule)
+	for _, k := range keys {
+		fmt.Fprintf(&b, ",%s=%s", k, labels[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package alerting
+
+import (
+	"context"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+// Source is the store the rules are evaluated against.
+//
+//go:generate moq -rm -out source_mock.go . Source
+type Source interface {
+	GetNodes(ctx context.Context) ([]kubeclient.Node, error)
+	GetPods(ctx context.Context, node string) ([]kubeclient.Pod, error)
+	GetHistory(ctx context.Context, since time.Time) ([]kubeclient.Sample, error)
+}
+
+// series is the value of a metric for one namespace, node or pod.
+type series struct {
+	labels map[string]string
+	value  float64
+}
+
+type snapshot struct {
+	nodes []kubeclient.Node
+	pods  []kubeclient.Pod
+}
+
+func (e *Engine) measure(ctx context.Context, rule Rule, snap snapshot, now time.Time) ([]series, error) {
+	switch rule.Metric {
+	case NamespaceMemoryUsage, NamespaceCPUUsage:
+		usage := make(map[string]float64)
+		for _, p := range snap.pods {
+			if rule.Namespace != "" && p.Namespace != rule.Namespace {
+				continue
+			}
+			if rule.Metric == NamespaceMemoryUsage {
+				usage[p.Namespace] += float64(p.MemoryUsage)
+			} else {
+				usage[p.Namespace] += float64(p.CPUUsage) / 1000
+			}
+		}
+		var result []series
+		for ns, value := range usage {
+			result = append(result, series{labels: map[string]string{"namespace": ns}, value: value})
+		}
+		return result, nil
+
+	case NodeCPUAllocation, NodeMemoryAllocation:
+		podsByNode := scheduler.Running(snap.pods)
+		var result []series
+		for _, n := range snap.nodes {
+			if rule.Node != "" && n.Name != rule.Node {
+				continue
+			}
+			state := scheduler.NewNodeState(n, podsByNode[n.Name])
+			value := ratio(state.CPURequest, n.AvailableCPU)
+			if rule.Metric == NodeMemoryAllocation {
+				value = ratio(state.MemoryRequest, n.AllocatableMemory)
+			}
+			result = append(result, series{labels: map[string]string{"node": n.Name}, value: value})
+		}
+		return result, nil
+
+	case PodRestarts:
+		history, err := e.source.GetHistory(ctx, now.Add(-rule.Window.Duration))
+		if err != nil {
+			return nil, err
+		}
+		restarts := kubeclient.RecentRestarts(history, snap.pods)
+		var result []series
+		for _, p := range snap.pods {
+			if rule.Namespace != "" && p.Namespace != rule.Namespace {
+				continue
+			}
+			// Pods not sampled yet have no known restarts in the window.
+			recent, ok := restarts[p.Namespace+"/"+p.Name]
+			if !ok {
+				continue
+			}
+			result = append(result, series{
+				labels: map[string]string{"namespace": p.Namespace, "pod": p.Name},
+				value:  float64(recent),
+			})
+		}
+		return result, nil
+
+	case PendingPods:
+		count := 0
+		for _, p := range snap.pods {
+			if p.Node == "" && p.Phase == "Pending" && (rule.Namespace == "" || p.Namespace == rule.Namespace) {
+				count++
+			}
+		}
+		labels := map[string]string{}
+		if rule.Namespace != "" {
+			labels["namespace"] = rule.Namespace
+		}
+		return []series{{labels: labels, value: float64(count)}}, nil
+	}
+	return nil, nil
+}
+
+func ratio(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total)
+}
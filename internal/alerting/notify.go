@@ -0,0 +1,168 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// notification is the payload of webhook receivers.
+type notification struct {
+	Status     string            `json:"status"`
+	Rule       string            `json:"rule"`
+	Metric     string            `json:"metric"`
+	Severity   string            `json:"severity,omitempty"`
+	Labels     map[string]string `json:"labels"`
+	Value      float64           `json:"value"`
+	Threshold  float64           `json:"threshold"`
+	ActiveAt   time.Time         `json:"activeAt"`
+	ResolvedAt *time.Time        `json:"resolvedAt,omitempty"`
+	Summary    string            `json:"summary"`
+}
+
+// notify sends the alert to the receivers of its rule, or only to the given
+// ones, and returns the receivers it failed to reach.
+func (e *Engine) notify(ctx context.Context, alert Alert, only []string) ([]string, error) {
+	var rule Rule
+	for _, r := range e.config.Rules {
+		if r.Name == alert.Rule {
+			rule = r
+		}
+	}
+	var failed, errs []string
+	for _, receiver := range e.config.Receivers {
+		if len(rule.Receivers) > 0 && !contains(rule.Receivers, receiver.Name) {
+			continue
+		}
+		if len(only) > 0 && !contains(only, receiver.Name) {
+			continue
+		}
+		if err := e.send(ctx, receiver, alert); err != nil {
+			failed = append(failed, receiver.Name)
+			errs = append(errs, fmt.Sprintf("%s: %v", receiver.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return failed, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil, nil
+}
+
+func (e *Engine) send(ctx context.Context, receiver Receiver, alert Alert) error {
+	body, err := json.Marshal(payload(receiver.Type, alert))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, receiver.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range receiver.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", receiver.URL, resp.Status)
+	}
+	return nil
+}
+
+// payload formats the alert for the receiver type. Slack and Teams incoming
+// webhooks take a message, Teams as a MessageCard.
+func payload(receiverType string, alert Alert) interface{} {
+	title := fmt.Sprintf("[%s] %s", strings.ToUpper(alert.State), alert.Rule)
+	switch receiverType {
+	case Slack:
+		return map[string]string{"text": title + ": " + Summary(alert)}
+	case Teams:
+		color := "D32F2F"
+		if alert.State == Resolved {
+			color = "388E3C"
+		}
+		return map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title,
+			"title":      title,
+			"text":       Summary(alert),
+			"themeColor": color,
+		}
+	default:
+		n := notification{
+			Status:    alert.State,
+			Rule:      alert.Rule,
+			Metric:    alert.Metric,
+			Severity:  alert.Severity,
+			Labels:    alert.Labels,
+			Value:     alert.Value,
+			Threshold: alert.Threshold,
+			ActiveAt:  alert.ActiveAt,
+			Summary:   Summary(alert),
+		}
+		if !alert.ResolvedAt.IsZero() {
+			n.ResolvedAt = &alert.ResolvedAt
+		}
+		return n
+	}
+}
+
+// Summary describes the alert, like "node_cpu_allocation of node=node1 is
+// 95%, above 90%".
+func Summary(alert Alert) string {
+	keys := make([]string, 0, len(alert.Labels))
+	for k := range alert.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	labels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		labels = append(labels, k+"="+alert.Labels[k])
+	}
+	subject := alert.Metric
+	if len(labels) > 0 {
+		subject += " of " + strings.Join(labels, ",")
+	}
+	if alert.State == Resolved {
+		return fmt.Sprintf("%s is back below %s", subject, Format(alert.Metric, alert.Threshold))
+	}
+	return fmt.Sprintf("%s is %s, above %s", subject, Format(alert.Metric, alert.Value), Format(alert.Metric, alert.Threshold))
+}
+
+// Format prints a value of the metric in its unit.
+func Format(metric string, value float64) string {
+	switch metric {
+	case NamespaceMemoryUsage:
+		units := []string{"", "Ki", "Mi", "Gi", "Ti", "Pi"}
+		i := 0
+		for value >= 1024 && i < len(units)-1 {
+			value /= 1024
+			i++
+		}
+		return fmt.Sprintf("%.4g%s", value, units[i])
+	case NamespaceCPUUsage:
+		return fmt.Sprintf("%.4g cores", value)
+	case NodeCPUAllocation, NodeMemoryAllocation:
+		return fmt.Sprintf("%.0f%%", value*100)
+	default:
+		return fmt.Sprintf("%.0f", value)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
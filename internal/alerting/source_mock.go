@@ -0,0 +1,177 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package alerting
+
+import (
+	"context"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"sync"
+	"time"
+)
+
+// Ensure, that SourceMock does implement Source.
+// If this is not the case, regenerate this file with moq.
+var _ Source = &SourceMock{}
+
+// SourceMock is a mock implementation of Source.
+//
+//	func TestSomethingThatUsesSource(t *testing.T) {
+//
+//		// make and configure a mocked Source
+//		mockedSource := &SourceMock{
+//			GetHistoryFunc: func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+//				panic("mock out the GetHistory method")
+//			},
+//			GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+//				panic("mock out the GetNodes method")
+//			},
+//			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+//				panic("mock out the GetPods method")
+//			},
+//		}
+//
+//		// use mockedSource in code that requires Source
+//		// and then make assertions.
+//
+//	}
+type SourceMock struct {
+	// GetHistoryFunc mocks the GetHistory method.
+	GetHistoryFunc func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error)
+
+	// GetNodesFunc mocks the GetNodes method.
+	GetNodesFunc func(ctx context.Context) ([]kubeclient.Node, error)
+
+	// GetPodsFunc mocks the GetPods method.
+	GetPodsFunc func(ctx context.Context, node string) ([]kubeclient.Pod, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetHistory holds details about calls to the GetHistory method.
+		GetHistory []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Since is the since argument value.
+			Since time.Time
+		}
+		// GetNodes holds details about calls to the GetNodes method.
+		GetNodes []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetPods holds details about calls to the GetPods method.
+		GetPods []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Node is the node argument value.
+			Node string
+		}
+	}
+	lockGetHistory sync.RWMutex
+	lockGetNodes   sync.RWMutex
+	lockGetPods    sync.RWMutex
+}
+
+// GetHistory calls GetHistoryFunc.
+func (mock *SourceMock) GetHistory(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+	if mock.GetHistoryFunc == nil {
+		panic("SourceMock.GetHistoryFunc: method is nil but Source.GetHistory was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Since time.Time
+	}{
+		Ctx:   ctx,
+		Since: since,
+	}
+	mock.lockGetHistory.Lock()
+	mock.calls.GetHistory = append(mock.calls.GetHistory, callInfo)
+	mock.lockGetHistory.Unlock()
+	return mock.GetHistoryFunc(ctx, since)
+}
+
+// GetHistoryCalls gets all the calls that were made to GetHistory.
+// Check the length with:
+//
+//	len(mockedSource.GetHistoryCalls())
+func (mock *SourceMock) GetHistoryCalls() []struct {
+	Ctx   context.Context
+	Since time.Time
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Since time.Time
+	}
+	mock.lockGetHistory.RLock()
+	calls = mock.calls.GetHistory
+	mock.lockGetHistory.RUnlock()
+	return calls
+}
+
+// GetNodes calls GetNodesFunc.
+func (mock *SourceMock) GetNodes(ctx context.Context) ([]kubeclient.Node, error) {
+	if mock.GetNodesFunc == nil {
+		panic("SourceMock.GetNodesFunc: method is nil but Source.GetNodes was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetNodes.Lock()
+	mock.calls.GetNodes = append(mock.calls.GetNodes, callInfo)
+	mock.lockGetNodes.Unlock()
+	return mock.GetNodesFunc(ctx)
+}
+
+// GetNodesCalls gets all the calls that were made to GetNodes.
+// Check the length with:
+//
+//	len(mockedSource.GetNodesCalls())
+func (mock *SourceMock) GetNodesCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetNodes.RLock()
+	calls = mock.calls.GetNodes
+	mock.lockGetNodes.RUnlock()
+	return calls
+}
+
+// GetPods calls GetPodsFunc.
+func (mock *SourceMock) GetPods(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+	if mock.GetPodsFunc == nil {
+		panic("SourceMock.GetPodsFunc: method is nil but Source.GetPods was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Node string
+	}{
+		Ctx:  ctx,
+		Node: node,
+	}
+	mock.lockGetPods.Lock()
+	mock.calls.GetPods = append(mock.calls.GetPods, callInfo)
+	mock.lockGetPods.Unlock()
+	return mock.GetPodsFunc(ctx, node)
+}
+
+// GetPodsCalls gets all the calls that were made to GetPods.
+// Check the length with:
+//
+//	len(mockedSource.GetPodsCalls())
+func (mock *SourceMock) GetPodsCalls() []struct {
+	Ctx  context.Context
+	Node string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Node string
+	}
+	mock.lockGetPods.RLock()
+	calls = mock.calls.GetPods
+	mock.lockGetPods.RUnlock()
+	return calls
+}
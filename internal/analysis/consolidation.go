@@ -0,0 +1,161 @@
+// Package analysis answers capacity questions about the whole cluster from the
+// nodes and pods in the store.
+package analysis
+
+import (
+	"sort"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+type Consolidation struct {
+	Nodes int
+	// NodesNeeded is how many of the nodes the pods could be packed on.
+	NodesNeeded int
+	Removable   []RemovableNode
+	Kept        []string
+	Before      Utilization
+	After       Utilization
+}
+
+type RemovableNode struct {
+	Name         string
+	InstanceType string
+	Moves        []Move
+}
+
+// Move is a pod going from a removed node to the node it would land on.
+type Move struct {
+	Pod  kubeclient.Pod
+	Node string
+}
+
+// Utilization is the share of the allocatable CPU and memory of a set of nodes
+// taken by the requests and the usage of their pods.
+type Utilization struct {
+	CPURequests    float64
+	MemoryRequests float64
+	CPUUsage       float64
+	MemoryUsage    float64
+}
+
+// Consolidate removes nodes one at a time, least requested first, as long as
+// the scheduler can place their pods on the remaining nodes. Removed nodes
+// take their DaemonSet and static pods with them. Nodes that are cordoned, or
+// run a pod without a controller, are kept.
+func Consolidate(nodes []kubeclient.Node, pods []kubeclient.Pod) Consolidation {
+	podsByNode := scheduler.Running(pods)
+	states := make([]*scheduler.NodeState, 0, len(nodes))
+	for _, n := range nodes {
+		states = append(states, scheduler.NewNodeState(n, podsByNode[n.Name]))
+	}
+	result := Consolidation{Nodes: len(nodes), Before: utilization(states)}
+
+	candidates := append([]*scheduler.NodeState(nil), states...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return requested(candidates[i]) < requested(candidates[j])
+	})
+
+	removed := make(map[string]bool)
+	for _, candidate := range candidates {
+		if candidate.Unschedulable {
+			continue
+		}
+		var remaining []*scheduler.NodeState
+		for _, n := range states {
+			if n != candidate && !removed[n.Name] {
+				remaining = append(remaining, n)
+			}
+		}
+		if moves, ok := evacuate(candidate, remaining); ok {
+			removed[candidate.Name] = true
+			result.Removable = append(result.Removable, RemovableNode{
+				Name:         candidate.Name,
+				InstanceType: candidate.InstanceType,
+				Moves:        moves,
+			})
+		}
+	}
+
+	var kept []*scheduler.NodeState
+	for _, n := range states {
+		if !removed[n.Name] {
+			kept = append(kept, n)
+			result.Kept = append(result.Kept, n.Name)
+		}
+	}
+	result.NodesNeeded = len(kept)
+	result.After = utilization(kept)
+	return result
+}
+
+// evacuate schedules the evictable pods of the node on the remaining nodes.
+// It only changes the remaining nodes when all pods fit.
+func evacuate(node *scheduler.NodeState, remaining []*scheduler.NodeState) ([]Move, bool) {
+	var evicted []kubeclient.Pod
+	for _, p := range node.Pods {
+		if ok, _ := scheduler.Evictable(p); !ok {
+			continue
+		}
+		if p.Workload == "" {
+			return nil, false
+		}
+		evicted = append(evicted, p)
+	}
+	sort.SliceStable(evicted, func(i, j int) bool {
+		if evicted[i].CPURequest != evicted[j].CPURequest {
+			return evicted[i].CPURequest > evicted[j].CPURequest
+		}
+		return evicted[i].MemoryRequest > evicted[j].MemoryRequest
+	})
+
+	var moves []Move
+	targets := make([]*scheduler.NodeState, 0, len(evicted))
+	for _, p := range evicted {
+		target, _ := scheduler.Schedule(p, remaining)
+		if target == nil {
+			for i, t := range targets {
+				t.Remove(evicted[i].Namespace, evicted[i].Name)
+			}
+			return nil, false
+		}
+		target.Add(p)
+		targets = append(targets, target)
+		moves = append(moves, Move{Pod: p, Node: target.Name})
+	}
+	return moves, true
+}
+
+func requested(n *scheduler.NodeState) float64 {
+	return (ratio(n.CPURequest, n.AvailableCPU) + ratio(n.MemoryRequest, n.AllocatableMemory)) / 2
+}
+
+// utilization counts the pods on the nodes, so pods moved by the
+// consolidation count where they landed.
+func utilization(nodes []*scheduler.NodeState) Utilization {
+	var cpu, memory, cpuRequests, memoryRequests, cpuUsage, memoryUsage int64
+	for _, n := range nodes {
+		cpu += n.AvailableCPU
+		memory += n.AllocatableMemory
+		cpuRequests += n.CPURequest
+		memoryRequests += n.MemoryRequest
+		for _, p := range n.Pods {
+			cpuUsage += p.CPUUsage
+			memoryUsage += p.MemoryUsage
+		}
+	}
+	return Utilization{
+		CPURequests:    ratio(cpuRequests, cpu),
+		MemoryRequests: ratio(memoryRequests, memory),
+		CPUUsage:       ratio(cpuUsage, cpu),
+		MemoryUsage:    ratio(memoryUsage, memory),
+	}
+}
+
+func ratio(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total)
+}
@@ -0,0 +1,72 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/analysis"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name string) kubeclient.Node {
+	return kubeclient.Node{Name: name, AvailableCPU: 2000, AllocatableMemory: 4 << 30, AllocatablePods: 110,
+		Labels: map[string]string{"kubernetes.io/hostname": name}}
+}
+
+func Test_Consolidate(t *testing.T) {
+	t.Run("given underused nodes, then remove the least requested ones that fit elsewhere", func(t *testing.T) {
+		nodes := []kubeclient.Node{node("node1"), node("node2"), node("node3")}
+		pods := []kubeclient.Pod{
+			{Name: "a", Node: "node1", Workload: "Deployment/a", CPURequest: 1000, CPUUsage: 500},
+			{Name: "b", Node: "node2", Workload: "Deployment/b", CPURequest: 600},
+			{Name: "c", Node: "node3", Workload: "Deployment/c", CPURequest: 300},
+			{Name: "logs-1", Node: "node1", Workload: "DaemonSet/logs", CPURequest: 100},
+			{Name: "logs-2", Node: "node2", Workload: "DaemonSet/logs", CPURequest: 100},
+			{Name: "logs-3", Node: "node3", Workload: "DaemonSet/logs", CPURequest: 100},
+		}
+
+		result := analysis.Consolidate(nodes, pods)
+		assert.Equal(t, 3, result.Nodes)
+		assert.Equal(t, 1, result.NodesNeeded)
+		assert.Equal(t, []string{"node1"}, result.Kept)
+		assert.Equal(t, "node3", result.Removable[0].Name)
+		assert.Equal(t, []analysis.Move{{Pod: pods[2], Node: "node2"}}, result.Removable[0].Moves)
+		assert.Equal(t, "node2", result.Removable[1].Name)
+		assert.Equal(t, 2, len(result.Removable[1].Moves))
+		assert.InDelta(t, 2200.0/6000, result.Before.CPURequests, 1e-9)
+		assert.InDelta(t, 2000.0/2000, result.After.CPURequests, 1e-9)
+		assert.InDelta(t, 500.0/2000, result.After.CPUUsage, 1e-9)
+	})
+
+	t.Run("given anti-affinity between replicas, then keep a node per replica", func(t *testing.T) {
+		antiAffinity := &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &v1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				TopologyKey:   "kubernetes.io/hostname",
+			}},
+		}}
+		web := func(name, node string) kubeclient.Pod {
+			return kubeclient.Pod{Name: name, Node: node, Workload: "Deployment/web", CPURequest: 100,
+				Labels: map[string]string{"app": "web"}, Affinity: antiAffinity}
+		}
+		nodes := []kubeclient.Node{node("node1"), node("node2"), node("node3")}
+		pods := []kubeclient.Pod{web("web-1", "node1"), web("web-2", "node2")}
+
+		result := analysis.Consolidate(nodes, pods)
+		assert.Equal(t, 2, result.NodesNeeded)
+		assert.Equal(t, "node3", result.Removable[0].Name)
+		assert.Empty(t, result.Removable[0].Moves)
+	})
+
+	t.Run("given pods without a controller or a cordoned node, then keep the node", func(t *testing.T) {
+		cordoned := node("node2")
+		cordoned.Unschedulable = true
+		nodes := []kubeclient.Node{node("node1"), cordoned, node("node3")}
+		pods := []kubeclient.Pod{{Name: "debug", Node: "node1"}}
+
+		result := analysis.Consolidate(nodes, pods)
+		assert.Equal(t, []string{"node1", "node2"}, result.Kept)
+	})
+}
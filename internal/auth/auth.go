@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type (
+	// Identity is the authenticated user attached to the request context.
+	Identity struct {
+		Name   string
+		Groups []string
+		Method string
+	}
+
+	// Authenticator resolves the identity of a request. It returns false when
+	// the request carries no credentials it understands, so the next
+	// authenticator can try, and an error when the credentials are invalid.
+	Authenticator interface {
+		Authenticate(r *http.Request) (Identity, bool, error)
+	}
+
+	// challenger is implemented by authenticators that can tell the client how
+	// to authenticate, e.g. with a WWW-Authenticate header or a login redirect.
+	challenger interface {
+		Challenge(w http.ResponseWriter, r *http.Request) bool
+	}
+
+	contextKey struct{}
+)
+
+var errUnauthenticated = errors.New("authentication required")
+
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(contextKey{}).(Identity)
+	return id, ok
+}
+
+// Middleware rejects requests that none of the authenticators accept and
+// attaches the identity of accepted requests to their context.
+func Middleware(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := authenticate(r, authenticators)
+			if err != nil {
+				unauthorized(w, r, authenticators, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+		})
+	}
+}
+
+// Audit logs the identity behind every request that reaches next.
+func Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := IdentityFromContext(r.Context())
+		log.Printf("audit: user=%q groups=%q method=%s %s %s", id.Name, strings.Join(id.Groups, ","), id.Method, r.Method, r.URL.RequestURI())
+		next.ServeHTTP(w, r)
+	})
+}
+
+func authenticate(r *http.Request, authenticators []Authenticator) (Identity, error) {
+	for _, a := range authenticators {
+		id, ok, err := a.Authenticate(r)
+		if err != nil {
+			return Identity{}, err
+		}
+		if ok {
+			return id, nil
+		}
+	}
+	return Identity{}, errUnauthenticated
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, authenticators []Authenticator, err error) {
+	for _, a := range authenticators {
+		if c, ok := a.(challenger); ok && c.Challenge(w, r) {
+			return
+		}
+	}
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(header[7:])
+	return token, token != ""
+}
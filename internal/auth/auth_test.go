@@ -0,0 +1,85 @@
+package auth_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func identityHandler(t *testing.T, expected *auth.Identity) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := auth.IdentityFromContext(r.Context())
+		assert.True(t, ok)
+		*expected = id
+	})
+}
+
+func Test_Middleware(t *testing.T) {
+	tokens, err := auth.ParseTokens(strings.NewReader("# static tokens\nsecret1,alice,1,\"dev,ops\"\nsecret2,bob\n"))
+	assert.Nil(t, err)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	htpasswd, err := auth.ParseHtpasswd(strings.NewReader(fmt.Sprintf("carol:%s\ndave:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n", hash)))
+	assert.Nil(t, err)
+
+	t.Run("given a known bearer token, then attach its identity", func(t *testing.T) {
+		var id auth.Identity
+		handler := auth.Middleware(tokens, htpasswd)(identityHandler(t, &id))
+		req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		req.Header.Set("Authorization", "Bearer secret1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, auth.Identity{Name: "alice", Groups: []string{"dev", "ops"}, Method: "token"}, id)
+	})
+
+	t.Run("given valid basic auth credentials, then attach the user", func(t *testing.T) {
+		var id auth.Identity
+		handler := auth.Middleware(tokens, htpasswd)(identityHandler(t, &id))
+		for _, user := range []string{"carol", "dave"} {
+			req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+			req.SetBasicAuth(user, "password")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, user, id.Name)
+			assert.Equal(t, "basic", id.Method)
+		}
+	})
+
+	t.Run("given a wrong password, then reject the request", func(t *testing.T) {
+		handler := auth.Middleware(tokens, htpasswd)(http.NotFoundHandler())
+		req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		req.SetBasicAuth("carol", "wrong")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("given no credentials, then challenge with every scheme", func(t *testing.T) {
+		handler := auth.Middleware(tokens, htpasswd)(http.NotFoundHandler())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nodes", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, 2, len(rec.Header().Values("WWW-Authenticate")))
+	})
+
+	t.Run("given an unknown bearer token, then reject the request", func(t *testing.T) {
+		handler := auth.Middleware(tokens)(http.NotFoundHandler())
+		req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		req.Header.Set("Authorization", "Bearer nope")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("given an unsupported htpasswd hash, then fail to load", func(t *testing.T) {
+		_, err := auth.ParseHtpasswd(strings.NewReader("erin:$apr1$abc$def\n"))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,23 @@
+package auth
+
+import "net/http"
+
+type clientCertAuthenticator struct{}
+
+// NewClientCertAuthenticator identifies requests by their verified TLS client
+// certificate, using the common name as the user and the organizations as the
+// groups, like the Kubernetes API server does.
+func NewClientCertAuthenticator() *clientCertAuthenticator {
+	return &clientCertAuthenticator{}
+}
+
+func (a *clientCertAuthenticator) Authenticate(r *http.Request) (Identity, bool, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return Identity{}, false, nil
+	}
+	subject := r.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return Identity{}, false, nil
+	}
+	return Identity{Name: subject.CommonName, Groups: subject.Organization, Method: "x509"}, true, nil
+}
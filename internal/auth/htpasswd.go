@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errInvalidCredentials = errors.New("invalid username or password")
+
+type htpasswdAuthenticator struct {
+	users map[string]string
+}
+
+// NewHtpasswdAuthenticator loads basic auth users from an htpasswd file.
+// Only bcrypt and {SHA} hashes are supported.
+func NewHtpasswdAuthenticator(path string) (*htpasswdAuthenticator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseHtpasswd(f)
+}
+
+func ParseHtpasswd(r io.Reader) (*htpasswdAuthenticator, error) {
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		user, hash, found := strings.Cut(text, ":")
+		if !found || user == "" {
+			return nil, fmt.Errorf("htpasswd line %d: expected user:hash", line)
+		}
+		if !isBcrypt(hash) && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("htpasswd line %d: unsupported hash for user %s, use bcrypt (htpasswd -B)", line, user)
+		}
+		users[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &htpasswdAuthenticator{users: users}, nil
+}
+
+func (a *htpasswdAuthenticator) Authenticate(r *http.Request) (Identity, bool, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return Identity{}, false, nil
+	}
+	hash, found := a.users[user]
+	if !found || !checkPassword(hash, password) {
+		return Identity{}, false, errInvalidCredentials
+	}
+	return Identity{Name: user, Method: "basic"}, true, nil
+}
+
+func (a *htpasswdAuthenticator) Challenge(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Add("WWW-Authenticate", `Basic realm="hawk8s", charset="UTF-8"`)
+	return false
+}
+
+func checkPassword(hash, password string) bool {
+	if isBcrypt(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	sum := sha1.Sum([]byte(password))
+	expected := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,472 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionCookie = "hawk8s_session"
+	stateCookie   = "hawk8s_oidc_state"
+	// keyRefreshInterval is the least time between two fetches of the
+	// issuer's keys.
+	keyRefreshInterval = time.Minute
+)
+
+type (
+	OIDCConfig struct {
+		IssuerURL     string
+		ClientID      string
+		ClientSecret  string
+		RedirectURL   string
+		UsernameClaim string
+		GroupsClaim   string
+		// SessionKey signs session cookies. A random key is generated when it
+		// is empty, which logs everyone out on restart.
+		SessionKey []byte
+		// SessionTTL caps the session lifetime; the ID token expiry is used
+		// when it is shorter.
+		SessionTTL time.Duration
+	}
+
+	OIDC struct {
+		config   OIDCConfig
+		client   *http.Client
+		provider oidcProvider
+		keys     map[string]*rsa.PublicKey
+		keysLock sync.RWMutex
+		// refreshLock serializes key refreshes, refreshed is when the keys
+		// were last fetched.
+		refreshLock sync.Mutex
+		refreshed   time.Time
+		now         func() time.Time
+	}
+
+	oidcProvider struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+
+	jsonWebKey struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+
+	session struct {
+		Name    string   `json:"name"`
+		Groups  []string `json:"groups,omitempty"`
+		Expires int64    `json:"exp"`
+	}
+
+	loginState struct {
+		State    string `json:"state"`
+		Nonce    string `json:"nonce"`
+		Redirect string `json:"redirect"`
+	}
+)
+
+// NewOIDC discovers the issuer configuration and keys. The login flow is the
+// authorization code flow; API clients may instead send the ID token as a
+// bearer token.
+func NewOIDC(ctx context.Context, config OIDCConfig) (*OIDC, error) {
+	if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("oidc: issuer url, client id and redirect url are required")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "email"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.SessionTTL == 0 {
+		config.SessionTTL = 12 * time.Hour
+	}
+	if len(config.SessionKey) == 0 {
+		config.SessionKey = make([]byte, 32)
+		if _, err := rand.Read(config.SessionKey); err != nil {
+			return nil, err
+		}
+	}
+
+	o := &OIDC{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]*rsa.PublicKey),
+		now:    time.Now,
+	}
+
+	wellKnown := strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := o.getJSON(ctx, wellKnown, &o.provider); err != nil {
+		return nil, fmt.Errorf("oidc: discovery: %w", err)
+	}
+	if o.provider.Issuer != config.IssuerURL {
+		return nil, fmt.Errorf("oidc: issuer %q does not match configured %q", o.provider.Issuer, config.IssuerURL)
+	}
+	if err := o.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *OIDC) Authenticate(r *http.Request) (Identity, bool, error) {
+	if token, ok := bearerToken(r); ok {
+		if strings.Count(token, ".") != 2 {
+			return Identity{}, false, nil
+		}
+		claims, err := o.verify(r.Context(), token, "")
+		if err != nil {
+			return Identity{}, false, err
+		}
+		id, err := o.identity(claims)
+		return id, err == nil, err
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return Identity{}, false, nil
+	}
+	var s session
+	if !o.decode(cookie.Value, &s) || s.Expires < o.now().Unix() {
+		// Stale sessions fall through to the login challenge.
+		return Identity{}, false, nil
+	}
+	return Identity{Name: s.Name, Groups: s.Groups, Method: "oidc"}, true, nil
+}
+
+// Challenge sends browsers to the login page. htmx requests get an HX-Redirect
+// so the whole page navigates instead of a fragment being swapped in.
+func (o *OIDC) Challenge(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/auth/login")
+		w.WriteHeader(http.StatusUnauthorized)
+		return true
+	}
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		return false
+	}
+	http.Redirect(w, r, "/auth/login?redirect="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+	return true
+}
+
+func (o *OIDC) Login(w http.ResponseWriter, r *http.Request) {
+	redirect := r.URL.Query().Get("redirect")
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+		redirect = "/"
+	}
+	state := loginState{State: randomString(), Nonce: randomString(), Redirect: redirect}
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    o.encode(state),
+		Path:     "/auth",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {o.config.ClientID},
+		"redirect_uri":  {o.config.RedirectURL},
+		"scope":         {"openid email profile"},
+		"state":         {state.State},
+		"nonce":         {state.Nonce},
+	}
+	http.Redirect(w, r, o.provider.AuthorizationEndpoint+"?"+query.Encode(), http.StatusFound)
+}
+
+func (o *OIDC) Callback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(stateCookie)
+	var state loginState
+	if err != nil || !o.decode(cookie.Value, &state) || state.State != r.URL.Query().Get("state") {
+		http.Error(w, "oidc: invalid login state", http.StatusBadRequest)
+		return
+	}
+	if msg := r.URL.Query().Get("error"); msg != "" {
+		http.Error(w, "oidc: "+msg, http.StatusUnauthorized)
+		return
+	}
+
+	rawIDToken, err := o.exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	claims, err := o.verify(r.Context(), rawIDToken, state.Nonce)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	id, err := o.identity(claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	expires := o.now().Add(o.config.SessionTTL)
+	if exp, ok := claims["exp"].(float64); ok && time.Unix(int64(exp), 0).Before(expires) {
+		expires = time.Unix(int64(exp), 0)
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/auth", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    o.encode(session{Name: id.Name, Groups: id.Groups, Expires: expires.Unix()}),
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, state.Redirect, http.StatusFound)
+}
+
+func (o *OIDC) Logout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+func (o *OIDC) exchange(ctx context.Context, code string) (string, error) {
+	if code == "" {
+		return "", errors.New("oidc: missing authorization code")
+	}
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {o.config.RedirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.config.ClientID), url.QueryEscape(o.config.ClientSecret))
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc: token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oidc: token exchange: %s", resp.Status)
+	}
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("oidc: token exchange: %w", err)
+	}
+	if token.IDToken == "" {
+		return "", errors.New("oidc: token response has no id_token")
+	}
+	return token.IDToken, nil
+}
+
+// verify checks the signature and standard claims of an RS256 ID token.
+func (o *OIDC) verify(ctx context.Context, token string, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oidc: malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("oidc: unsupported signing algorithm %q", header.Alg)
+	}
+	key, err := o.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("oidc: malformed token signature")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, errors.New("oidc: invalid token signature")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if claims["iss"] != o.provider.Issuer {
+		return nil, errors.New("oidc: unexpected token issuer")
+	}
+	if !audienceContains(claims["aud"], o.config.ClientID) {
+		return nil, errors.New("oidc: token was not issued for this client")
+	}
+	if exp, ok := claims["exp"].(float64); !ok || int64(exp) < o.now().Unix() {
+		return nil, errors.New("oidc: token expired")
+	}
+	if nonce != "" && claims["nonce"] != nonce {
+		return nil, errors.New("oidc: nonce mismatch")
+	}
+	return claims, nil
+}
+
+func (o *OIDC) identity(claims map[string]interface{}) (Identity, error) {
+	name, _ := claims[o.config.UsernameClaim].(string)
+	if name == "" {
+		return Identity{}, fmt.Errorf("oidc: token has no %q claim", o.config.UsernameClaim)
+	}
+	id := Identity{Name: name, Method: "oidc"}
+	switch groups := claims[o.config.GroupsClaim].(type) {
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	case string:
+		id.Groups = []string{groups}
+	}
+	return id, nil
+}
+
+func (o *OIDC) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	o.keysLock.RLock()
+	key, ok := o.keys[kid]
+	o.keysLock.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	// The issuer may have rotated its keys since the last fetch. Tokens with
+	// made up key IDs must not make every request fetch the keys, so they are
+	// fetched at most every keyRefreshInterval.
+	o.refreshLock.Lock()
+	defer o.refreshLock.Unlock()
+	if o.now().Sub(o.refreshed) >= keyRefreshInterval {
+		if err := o.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+	}
+	o.keysLock.RLock()
+	defer o.keysLock.RUnlock()
+	if key, ok := o.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+}
+
+func (o *OIDC) refreshKeys(ctx context.Context) error {
+	o.refreshed = o.now()
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := o.getJSON(ctx, o.provider.JWKSURI, &jwks); err != nil {
+		return fmt.Errorf("oidc: fetching keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	o.keysLock.Lock()
+	defer o.keysLock.Unlock()
+	o.keys = keys
+	return nil
+}
+
+func (o *OIDC) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// encode serializes v into a cookie value signed with the session key.
+func (o *OIDC) encode(v interface{}) string {
+	payload, _ := json.Marshal(v)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + o.sign(encoded)
+}
+
+func (o *OIDC) decode(value string, v interface{}) bool {
+	encoded, signature, found := strings.Cut(value, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(o.sign(encoded))) {
+		return false
+	}
+	return decodeSegment(encoded, v) == nil
+}
+
+func (o *OIDC) sign(s string) string {
+	mac := hmac.New(sha256.New, o.config.SessionKey)
+	mac.Write([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("oidc: malformed token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("oidc: malformed token")
+	}
+	return nil
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,182 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIssuer is a minimal OIDC provider that issues an ID token for every
+// authorization code it receives.
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	nonce  string
+	// kid signs the tokens, "test" by default.
+	kid         string
+	keyRequests atomic.Int32
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	m := &mockIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		m.keyRequests.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "valid-code" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.token(t, "hawk8s", time.Hour)})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockIssuer) token(t *testing.T, audience string, validity time.Duration) string {
+	kid := m.kid
+	if kid == "" {
+		kid = "test"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":    m.server.URL,
+		"aud":    audience,
+		"exp":    time.Now().Add(validity).Unix(),
+		"email":  "alice@example.com",
+		"groups": []string{"team-a"},
+		"nonce":  m.nonce,
+	})
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, digest[:])
+	assert.Nil(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func Test_OIDC(t *testing.T) {
+	issuer := newMockIssuer(t)
+	oidc, err := auth.NewOIDC(context.Background(), auth.OIDCConfig{
+		IssuerURL:   issuer.server.URL,
+		ClientID:    "hawk8s",
+		RedirectURL: "http://localhost:3000/auth/callback",
+	})
+	assert.Nil(t, err)
+
+	t.Run("given a valid ID token as bearer, then attach its identity", func(t *testing.T) {
+		var id auth.Identity
+		handler := auth.Middleware(oidc)(identityHandler(t, &id))
+		req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		req.Header.Set("Authorization", "Bearer "+issuer.token(t, "hawk8s", time.Hour))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, auth.Identity{Name: "alice@example.com", Groups: []string{"team-a"}, Method: "oidc"}, id)
+	})
+
+	t.Run("given an expired or foreign ID token, then reject the request", func(t *testing.T) {
+		handler := auth.Middleware(oidc)(http.NotFoundHandler())
+		for _, token := range []string{issuer.token(t, "hawk8s", -time.Minute), issuer.token(t, "other", time.Hour)} {
+			req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+
+	t.Run("given tokens with unknown key IDs, then reject them without refetching the keys", func(t *testing.T) {
+		issuer.kid = "unknown"
+		defer func() { issuer.kid = "" }()
+		requests := issuer.keyRequests.Load()
+		handler := auth.Middleware(oidc)(http.NotFoundHandler())
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+			req.Header.Set("Authorization", "Bearer "+issuer.token(t, "hawk8s", time.Hour))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+		assert.Equal(t, requests, issuer.keyRequests.Load())
+	})
+
+	t.Run("given an unauthenticated browser, then redirect to login", func(t *testing.T) {
+		handler := auth.Middleware(oidc)(http.NotFoundHandler())
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "text/html")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "/auth/login?redirect=%2F", rec.Header().Get("Location"))
+	})
+
+	t.Run("given a completed login, then the session cookie authenticates", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		oidc.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/login?redirect=/nodes", nil))
+		assert.Equal(t, http.StatusFound, rec.Code)
+		location, _ := url.Parse(rec.Header().Get("Location"))
+		assert.Equal(t, issuer.server.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+		issuer.nonce = location.Query().Get("nonce")
+
+		callback := httptest.NewRequest(http.MethodGet, "/auth/callback?code=valid-code&state="+location.Query().Get("state"), nil)
+		for _, c := range rec.Result().Cookies() {
+			callback.AddCookie(c)
+		}
+		rec = httptest.NewRecorder()
+		oidc.Callback(rec, callback)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "/nodes", rec.Header().Get("Location"))
+
+		var id auth.Identity
+		req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		for _, c := range rec.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		rec = httptest.NewRecorder()
+		auth.Middleware(oidc)(identityHandler(t, &id)).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "alice@example.com", id.Name)
+	})
+
+	t.Run("given a callback with a forged state, then reject it", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		oidc.Callback(rec, httptest.NewRequest(http.MethodGet, "/auth/callback?code=valid-code&state=forged", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
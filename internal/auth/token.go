@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type tokenAuthenticator struct {
+	tokens map[string]Identity
+}
+
+// NewTokenAuthenticator loads static bearer tokens from a CSV file in the
+// kube-apiserver token file format: token,user[,uid[,"group1,group2"]].
+func NewTokenAuthenticator(path string) (*tokenAuthenticator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseTokens(f)
+}
+
+func ParseTokens(r io.Reader) (*tokenAuthenticator, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	tokens := make(map[string]Identity)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("token file line %d: expected at least token and user", line)
+		}
+		id := Identity{Name: record[1], Method: "token"}
+		if len(record) > 3 {
+			for _, group := range strings.Split(record[3], ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					id.Groups = append(id.Groups, group)
+				}
+			}
+		}
+		tokens[record[0]] = id
+	}
+	return &tokenAuthenticator{tokens: tokens}, nil
+}
+
+func (a *tokenAuthenticator) Authenticate(r *http.Request) (Identity, bool, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return Identity{}, false, nil
+	}
+	for t, id := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return id, true, nil
+		}
+	}
+	// Unknown tokens may still be OIDC ID tokens, so leave them to the next
+	// authenticator instead of rejecting the request here.
+	return Identity{}, false, nil
+}
+
+func (a *tokenAuthenticator) Challenge(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Add("WWW-Authenticate", `Bearer realm="hawk8s"`)
+	return false
+}
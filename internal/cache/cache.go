@@ -1,10 +1,14 @@
 package cache
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type cache struct {
 	data     map[string]cacheValue
 	validity int
+	lock     sync.Mutex
 }
 
 type cacheValue struct {
@@ -20,6 +24,9 @@ func New(validityInSeconds int) *cache {
 }
 
 func (c *cache) Get(key string) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	val, ok := c.data[key]
 	if val.created+int64(c.validity) < time.Now().Unix() {
 		delete(c.data, key)
@@ -29,6 +36,9 @@ func (c *cache) Get(key string) (interface{}, bool) {
 }
 
 func (c *cache) Set(key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	c.data[key] = cacheValue{
 		data:    value,
 		created: time.Now().Unix(),
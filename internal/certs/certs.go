@@ -0,0 +1,224 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	RequireClientCert       = "require"
+	VerifyClientCertIfGiven = "verify-if-given"
+)
+
+type Options struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS, verifying client certificates against
+	// the CA bundle in the file.
+	ClientCAFile string
+	ClientAuth   string
+	// SelfSigned generates a certificate for Hosts when no certificate file is
+	// given.
+	SelfSigned bool
+	Hosts      []string
+	// ReloadInterval is how often the certificate files are checked for
+	// changes.
+	ReloadInterval time.Duration
+}
+
+// reloader serves the certificate in certFile and keyFile, loading it again
+// whenever either file changes.
+type reloader struct {
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+	modTime  time.Time
+	lock     sync.RWMutex
+}
+
+// NewConfig builds the TLS configuration for the server. It returns nil when
+// TLS is not enabled.
+func NewConfig(ctx context.Context, opts Options) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	switch {
+	case opts.CertFile != "" || opts.KeyFile != "":
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, errors.New("both a certificate and a key file are required")
+		}
+		r, err := NewReloader(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		interval := opts.ReloadInterval
+		if interval == 0 {
+			interval = 10 * time.Second
+		}
+		go r.Watch(ctx, interval)
+		config.GetCertificate = r.GetCertificate
+	case opts.SelfSigned:
+		cert, err := SelfSigned(opts.Hosts)
+		if err != nil {
+			return nil, err
+		}
+		fingerprint := sha256.Sum256(cert.Certificate[0])
+		log.Printf("Generated self-signed certificate, SHA-256 fingerprint %X", fingerprint)
+		config.Certificates = []tls.Certificate{cert}
+	default:
+		if opts.ClientCAFile != "" {
+			return nil, errors.New("client certificate verification requires TLS")
+		}
+		return nil, nil
+	}
+
+	if opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.ClientCAFile)
+		}
+		switch opts.ClientAuth {
+		case "", RequireClientCert:
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		case VerifyClientCertIfGiven:
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("unknown client auth mode %q", opts.ClientAuth)
+		}
+	}
+	return config, nil
+}
+
+func NewReloader(certFile, keyFile string) (*reloader, error) {
+	r := &reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.cert, nil
+}
+
+// Reload loads the certificate again if either file changed since the last
+// load, and reports whether it did. A failed load keeps serving the previous
+// certificate.
+func (r *reloader) Reload() (bool, error) {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.lock.RLock()
+	unchanged := r.cert != nil && modTime.Equal(r.modTime)
+	r.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cert = &cert
+	r.modTime = modTime
+	return true, nil
+}
+
+func (r *reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				log.Printf("Reloading certificate %s: %v", r.certFile, err)
+			} else if reloaded {
+				log.Printf("Reloaded certificate %s", r.certFile)
+			}
+		}
+	}
+}
+
+// SelfSigned generates a certificate valid for a year for the given host
+// names and IP addresses, defaulting to localhost.
+func SelfSigned(hosts []string) (tls.Certificate, error) {
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hosts[0], Organization: []string{"hawk8s"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,146 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/certs"
+	"github.com/stretchr/testify/assert"
+)
+
+// issue creates a certificate signed by parent, or a self-signed CA when
+// parent is nil, and returns it with its PEM encoded certificate and key.
+func issue(t *testing.T, name string, parent *tls.Certificate) (tls.Certificate, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.Nil(t, err)
+	leaf, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func Test_Reloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	write := func(name string, modTime time.Time) {
+		_, certPEM, keyPEM := issue(t, name, nil)
+		assert.Nil(t, os.WriteFile(certFile, certPEM, 0600))
+		assert.Nil(t, os.WriteFile(keyFile, keyPEM, 0600))
+		assert.Nil(t, os.Chtimes(certFile, modTime, modTime))
+		assert.Nil(t, os.Chtimes(keyFile, modTime, modTime))
+	}
+	served := func(r interface {
+		GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error)
+	}) string {
+		cert, err := r.GetCertificate(nil)
+		assert.Nil(t, err)
+		leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+		return leaf.Subject.CommonName
+	}
+
+	t.Run("given unchanged files, then keep the certificate", func(t *testing.T) {
+		write("first", time.Now().Add(-time.Minute))
+		r, err := certs.NewReloader(certFile, keyFile)
+		assert.Nil(t, err)
+		reloaded, err := r.Reload()
+		assert.Nil(t, err)
+		assert.False(t, reloaded)
+		assert.Equal(t, "first", served(r))
+	})
+
+	t.Run("given rotated files, then serve the new certificate", func(t *testing.T) {
+		write("first", time.Now().Add(-time.Minute))
+		r, err := certs.NewReloader(certFile, keyFile)
+		assert.Nil(t, err)
+
+		write("second", time.Now())
+		reloaded, err := r.Reload()
+		assert.Nil(t, err)
+		assert.True(t, reloaded)
+		assert.Equal(t, "second", served(r))
+	})
+
+	t.Run("given a broken key file, then keep the previous certificate", func(t *testing.T) {
+		write("first", time.Now().Add(-time.Minute))
+		r, err := certs.NewReloader(certFile, keyFile)
+		assert.Nil(t, err)
+
+		assert.Nil(t, os.WriteFile(keyFile, []byte("garbage"), 0600))
+		_, err = r.Reload()
+		assert.NotNil(t, err)
+		assert.Equal(t, "first", served(r))
+	})
+}
+
+func Test_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caPEM, _ := issue(t, "hawk8s-ca", nil)
+	caFile := filepath.Join(dir, "ca.crt")
+	assert.Nil(t, os.WriteFile(caFile, caPEM, 0600))
+	clientCert, _, _ := issue(t, "alice", &ca)
+	stranger, _, _ := issue(t, "mallory", nil)
+
+	config, err := certs.NewConfig(context.Background(), certs.Options{SelfSigned: true, ClientCAFile: caFile})
+	assert.Nil(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = config
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(cert *tls.Certificate) (*http.Response, error) {
+		tlsConfig := &tls.Config{InsecureSkipVerify: true}
+		if cert != nil {
+			tlsConfig.Certificates = []tls.Certificate{*cert}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		return client.Get(server.URL)
+	}
+
+	t.Run("given a client certificate signed by the CA, then accept the connection", func(t *testing.T) {
+		resp, err := get(&clientCert)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	})
+
+	t.Run("given no or a foreign client certificate, then reject the connection", func(t *testing.T) {
+		_, err := get(nil)
+		assert.NotNil(t, err)
+		_, err = get(&stranger)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,68 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package core
+
+import (
+	"github.com/jawahars16/hawk8s/internal/alerting"
+	"sync"
+)
+
+// Ensure, that AlertingMock does implement Alerting.
+// If this is not the case, regenerate this file with moq.
+var _ Alerting = &AlertingMock{}
+
+// AlertingMock is a mock implementation of Alerting.
+//
+//	func TestSomethingThatUsesAlerting(t *testing.T) {
+//
+//		// make and configure a mocked Alerting
+//		mockedAlerting := &AlertingMock{
+//			StatusFunc: func() ([]alerting.RuleStatus, error) {
+//				panic("mock out the Status method")
+//			},
+//		}
+//
+//		// use mockedAlerting in code that requires Alerting
+//		// and then make assertions.
+//
+//	}
+type AlertingMock struct {
+	// StatusFunc mocks the Status method.
+	StatusFunc func() ([]alerting.RuleStatus, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Status holds details about calls to the Status method.
+		Status []struct {
+		}
+	}
+	lockStatus sync.RWMutex
+}
+
+// Status calls StatusFunc.
+func (mock *AlertingMock) Status() ([]alerting.RuleStatus, error) {
+	if mock.StatusFunc == nil {
+		panic("AlertingMock.StatusFunc: method is nil but Alerting.Status was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockStatus.Lock()
+	mock.calls.Status = append(mock.calls.Status, callInfo)
+	mock.lockStatus.Unlock()
+	return mock.StatusFunc()
+}
+
+// StatusCalls gets all the calls that were made to Status.
+// Check the length with:
+//
+//	len(mockedAlerting.StatusCalls())
+func (mock *AlertingMock) StatusCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockStatus.RLock()
+	calls = mock.calls.Status
+	mock.lockStatus.RUnlock()
+	return calls
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jawahars16/hawk8s/internal/alerting"
+)
+
+var ErrNoAlerting = errors.New("no alerting rules configured, start hawk8s with --alert-rules")
+
+// Alerting reports the state of the alerting rules.
+//
+//go:generate moq -rm -out alerting_mock.go . Alerting
+type Alerting interface {
+	Status() ([]alerting.RuleStatus, error)
+}
+
+type (
+	alertsViewModel struct {
+		Rules []alertRule
+		Error string
+	}
+	alertRule struct {
+		Name      string
+		Metric    string
+		Threshold string
+		For       string
+		Severity  string
+		// State is the state of the most severe alert, ok without alerts.
+		State  string
+		Alerts []alert
+		// Hidden counts the alerts about namespaces the user may not see.
+		Hidden int
+	}
+	alert struct {
+		State   string
+		Labels  string
+		Value   string
+		Summary string
+		Since   string
+		Error   string
+	}
+)
+
+func WithAlerting(alerting Alerting) Option {
+	return func(s *Service) {
+		s.alerting = alerting
+	}
+}
+
+// GetAlerts returns the alerting rules with their alerts, leaving out the
+// alerts about namespaces or nodes the user may not see.
+func (s *Service) GetAlerts(ctx context.Context) ([]alertRule, error) {
+	if s.alerting == nil {
+		return nil, ErrNoAlerting
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.alerting.Status()
+	now := s.now()
+
+	rules := make([]alertRule, 0, len(status))
+	for _, rs := range status {
+		rule := alertRule{
+			Name:      rs.Rule.Name,
+			Metric:    rs.Rule.Metric,
+			Threshold: alerting.Format(rs.Rule.Metric, rs.Rule.Threshold.AsApproximateFloat64()),
+			Severity:  rs.Rule.Severity,
+			State:     "ok",
+		}
+		if rs.Rule.For.Duration > 0 {
+			rule.For = rs.Rule.For.String()
+		}
+		for _, a := range rs.Alerts {
+			if !alertVisible(access, a) {
+				rule.Hidden++
+				continue
+			}
+			since := a.ActiveAt
+			if a.State == alerting.Resolved {
+				since = a.ResolvedAt
+			}
+			rule.Alerts = append(rule.Alerts, alert{
+				State:   a.State,
+				Labels:  alertLabels(a.Labels),
+				Value:   alerting.Format(a.Metric, a.Value),
+				Summary: alerting.Summary(a),
+				Since:   age(now.Sub(since)),
+				Error:   a.Error,
+			})
+			// Alerts are sorted firing first.
+			if rule.State == "ok" {
+				rule.State = a.State
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, err
+}
+
+// alertVisible shows alerts about a namespace or pod to the users who may see
+// the namespace, and the other alerts to users who may list nodes.
+func alertVisible(access *accessScope, a alerting.Alert) bool {
+	if ns, ok := a.Labels["namespace"]; ok {
+		return access.namespace(ns)
+	}
+	return access == nil || access.allNodes
+}
+
+func alertLabels(labels map[string]string) string {
+	result := make([]string, 0, len(labels))
+	for k, v := range labels {
+		result = append(result, k+"="+v)
+	}
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
+
+func (h *Handler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetAlerts(r.Context())
+	vm := alertsViewModel{Rules: rules}
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "alerts.html", vm)
+}
@@ -0,0 +1,83 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/alerting"
+	"github.com/jawahars16/hawk8s/internal/auth"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_Alerts(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"team-a", "team-b"}, nil
+		},
+	}
+	alerts := &core.AlertingMock{
+		StatusFunc: func() ([]alerting.RuleStatus, error) {
+			return []alerting.RuleStatus{
+				{
+					Rule: alerting.Rule{Name: "memory", Metric: alerting.NamespaceMemoryUsage, Threshold: resource.MustParse("1Gi")},
+					Alerts: []alerting.Alert{
+						{Rule: "memory", Metric: alerting.NamespaceMemoryUsage, State: alerting.Firing, Labels: map[string]string{"namespace": "team-a"}, Value: 2 << 30, Threshold: 1 << 30, ActiveAt: time.Now()},
+						{Rule: "memory", Metric: alerting.NamespaceMemoryUsage, State: alerting.Pending, Labels: map[string]string{"namespace": "team-b"}, Value: 3 << 30, Threshold: 1 << 30, ActiveAt: time.Now()},
+					},
+				},
+				{
+					Rule: alerting.Rule{Name: "node-cpu", Metric: alerting.NodeCPUAllocation, Threshold: resource.MustParse("0.9"), For: alerting.Duration{Duration: 10 * time.Minute}},
+				},
+			}, nil
+		},
+	}
+
+	t.Run("given no alerting rules, then return an error", func(t *testing.T) {
+		_, err := core.NewService(kube).GetAlerts(context.Background())
+		assert.ErrorIs(t, err, core.ErrNoAlerting)
+	})
+
+	t.Run("given alerting rules, then list them with their alerts", func(t *testing.T) {
+		rules, err := core.NewService(kube, core.WithAlerting(alerts)).GetAlerts(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(rules))
+		assert.Equal(t, "firing", rules[0].State)
+		assert.Equal(t, 2, len(rules[0].Alerts))
+		assert.Equal(t, "namespace=team-a", rules[0].Alerts[0].Labels)
+		assert.Equal(t, "2Gi", rules[0].Alerts[0].Value)
+		assert.Equal(t, "ok", rules[1].State)
+		assert.Equal(t, "90%", rules[1].Threshold)
+		assert.Equal(t, "10m0s", rules[1].For)
+	})
+
+	t.Run("given a user limited to a namespace, then hide the other alerts", func(t *testing.T) {
+		authorizer := &core.AuthorizerMock{
+			CanListFunc: func(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+				return resource == "pods" && namespace == "team-b", nil
+			},
+		}
+		ctx := auth.WithIdentity(context.Background(), auth.Identity{Name: "alice"})
+		service := core.NewService(kube, core.WithAlerting(alerts), core.WithAuthorization(authorizer, nil, nil))
+		rules, err := service.GetAlerts(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rules[0].Alerts))
+		assert.Equal(t, "pending", rules[0].State)
+		assert.Equal(t, 1, rules[0].Hidden)
+	})
+
+	t.Run("given the alerts page, then render the rules", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), core.NewService(kube, core.WithAlerting(alerts)))
+		req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetAlerts(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "namespace_memory_usage of namespace=team-a is 2Gi, above 1Gi")
+		assert.Contains(t, rec.Body.String(), "node_cpu_allocation above 90% for 10m0s")
+	})
+}
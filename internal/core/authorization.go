@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jawahars16/hawk8s/internal/auth"
+)
+
+var ErrUnauthenticated = errors.New("authorization requires an authenticated user")
+
+//go:generate moq -rm -out authorizer_mock.go . Authorizer
+type Authorizer interface {
+	CanList(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error)
+}
+
+type authorization struct {
+	authorizer  Authorizer
+	adminUsers  map[string]bool
+	adminGroups map[string]bool
+}
+
+// accessScope is what the user behind a request may see. A nil scope, used
+// when authorization is disabled or the user is an admin, sees everything.
+type accessScope struct {
+	allNamespaces bool
+	allNodes      bool
+	namespaces    map[string]bool
+}
+
+// WithAuthorization limits nodes, pods and namespaces to those the
+// authenticated user may list. Admin users and groups bypass the checks.
+func WithAuthorization(authorizer Authorizer, adminUsers []string, adminGroups []string) Option {
+	return func(s *Service) {
+		s.authz = &authorization{
+			authorizer:  authorizer,
+			adminUsers:  toSet(adminUsers),
+			adminGroups: toSet(adminGroups),
+		}
+	}
+}
+
+func (a *accessScope) namespace(ns string) bool {
+	return a == nil || a.allNamespaces || a.namespaces[ns]
+}
+
+func (s *Service) access(ctx context.Context) (*accessScope, error) {
+	if s.authz == nil {
+		return nil, nil
+	}
+	id, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if s.authz.isAdmin(id) {
+		return nil, nil
+	}
+
+	scope := &accessScope{namespaces: make(map[string]bool)}
+	var err error
+	scope.allNodes, err = s.authz.authorizer.CanList(ctx, id.Name, id.Groups, "nodes", "")
+	if err != nil {
+		return nil, err
+	}
+	scope.allNamespaces, err = s.authz.authorizer.CanList(ctx, id.Name, id.Groups, "pods", "")
+	if err != nil || scope.allNamespaces {
+		return scope, err
+	}
+
+	namespaces, err := s.kube.GetNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces {
+		allowed, err := s.authz.authorizer.CanList(ctx, id.Name, id.Groups, "pods", ns)
+		if err != nil {
+			return nil, err
+		}
+		scope.namespaces[ns] = allowed
+	}
+	return scope, nil
+}
+
+// visibleNodes returns the nodes running pods the user can see, or nil when
+// the user may list all nodes.
+func (s *Service) visibleNodes(ctx context.Context, access *accessScope) (map[string]bool, error) {
+	if access == nil || access.allNodes {
+		return nil, nil
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, err
+	}
+	nodes := make(map[string]bool)
+	for _, p := range pods {
+		if p.Node != "" && access.namespace(p.Namespace) {
+			nodes[p.Node] = true
+		}
+	}
+	return nodes, nil
+}
+
+func (a *authorization) isAdmin(id auth.Identity) bool {
+	if a.adminUsers[id.Name] {
+		return true
+	}
+	for _, group := range id.Groups {
+		if a.adminGroups[group] {
+			return true
+		}
+	}
+	return false
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
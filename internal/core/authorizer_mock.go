@@ -0,0 +1,99 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// Ensure, that AuthorizerMock does implement Authorizer.
+// If this is not the case, regenerate this file with moq.
+var _ Authorizer = &AuthorizerMock{}
+
+// AuthorizerMock is a mock implementation of Authorizer.
+//
+//	func TestSomethingThatUsesAuthorizer(t *testing.T) {
+//
+//		// make and configure a mocked Authorizer
+//		mockedAuthorizer := &AuthorizerMock{
+//			CanListFunc: func(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+//				panic("mock out the CanList method")
+//			},
+//		}
+//
+//		// use mockedAuthorizer in code that requires Authorizer
+//		// and then make assertions.
+//
+//	}
+type AuthorizerMock struct {
+	// CanListFunc mocks the CanList method.
+	CanListFunc func(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CanList holds details about calls to the CanList method.
+		CanList []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// User is the user argument value.
+			User string
+			// Groups is the groups argument value.
+			Groups []string
+			// Resource is the resource argument value.
+			Resource string
+			// Namespace is the namespace argument value.
+			Namespace string
+		}
+	}
+	lockCanList sync.RWMutex
+}
+
+// CanList calls CanListFunc.
+func (mock *AuthorizerMock) CanList(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+	if mock.CanListFunc == nil {
+		panic("AuthorizerMock.CanListFunc: method is nil but Authorizer.CanList was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		User      string
+		Groups    []string
+		Resource  string
+		Namespace string
+	}{
+		Ctx:       ctx,
+		User:      user,
+		Groups:    groups,
+		Resource:  resource,
+		Namespace: namespace,
+	}
+	mock.lockCanList.Lock()
+	mock.calls.CanList = append(mock.calls.CanList, callInfo)
+	mock.lockCanList.Unlock()
+	return mock.CanListFunc(ctx, user, groups, resource, namespace)
+}
+
+// CanListCalls gets all the calls that were made to CanList.
+// Check the length with:
+//
+//	len(mockedAuthorizer.CanListCalls())
+func (mock *AuthorizerMock) CanListCalls() []struct {
+	Ctx       context.Context
+	User      string
+	Groups    []string
+	Resource  string
+	Namespace string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		User      string
+		Groups    []string
+		Resource  string
+		Namespace string
+	}
+	mock.lockCanList.RLock()
+	calls = mock.calls.CanList
+	mock.lockCanList.RUnlock()
+	return calls
+}
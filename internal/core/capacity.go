@@ -0,0 +1,45 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+// ephemeralSize is the share of the node's allocatable ephemeral storage the
+// pod requests, which finished pods have given back.
+func ephemeralSize(p kubeclient.Pod, n kubeclient.Node) string {
+	if scheduler.Finished(p) || p.EphemeralStorageRequest == 0 || n.AllocatableEphemeralStorage == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%v%%", max(float32(percent(p.EphemeralStorageRequest, n.AllocatableEphemeralStorage)), 0.5))
+}
+
+// slotSize is the share of the node's pod slots one pod takes, which
+// finished pods have given back.
+func slotSize(p kubeclient.Pod, n kubeclient.Node) string {
+	if scheduler.Finished(p) || n.AllocatablePods == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%v%%", float32(percent(1, n.AllocatablePods)))
+}
+
+// nodeCapacity sums what the pods of the node request of its ephemeral
+// storage and pod slots, the limits the scheduler also checks besides CPU and
+// memory.
+func nodeCapacity(model *node, n kubeclient.Node, pods []kubeclient.Pod) {
+	var ephemeral int64
+	for _, p := range pods {
+		if scheduler.Finished(p) {
+			continue
+		}
+		ephemeral += p.EphemeralStorageRequest
+		model.PodCount++
+	}
+	model.MaxPods = n.AllocatablePods
+	model.PodSlotsPercent = percent(int64(model.PodCount), n.AllocatablePods)
+	model.EphemeralStorage = memoryBytesToHumanReadable(n.AllocatableEphemeralStorage)
+	model.EphemeralRequest = memoryBytesToHumanReadable(ephemeral)
+	model.EphemeralRequestPercent = percent(ephemeral, n.AllocatableEphemeralStorage)
+}
@@ -0,0 +1,98 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Capacity(t *testing.T) {
+	nodes := []kubeclient.Node{
+		{Name: "node-1", AvailableCPU: 4000, AllocatableMemory: 16 << 30, AllocatablePods: 4, AllocatableEphemeralStorage: 40 << 30},
+		{Name: "node-2", AvailableCPU: 4000, AllocatableMemory: 16 << 30, AllocatablePods: 110, AllocatableEphemeralStorage: 100 << 30},
+	}
+	pods := []kubeclient.Pod{
+		{Name: "build-1", Namespace: "ci", Node: "node-1", Phase: "Running", CPUUsage: 10, EphemeralStorageRequest: 10 << 30},
+		{Name: "build-2", Namespace: "ci", Node: "node-1", Phase: "Running", CPUUsage: 10, EphemeralStorageRequest: 20 << 30},
+		{Name: "job", Namespace: "ci", Node: "node-1", Phase: "Succeeded", EphemeralStorageRequest: 5 << 30},
+		{Name: "agent-1", Namespace: "kube-system", Node: "node-1", Phase: "Running"},
+		{Name: "agent-2", Namespace: "kube-system", Node: "node-1", Phase: "Running"},
+		{Name: "web", Namespace: "shop", Node: "node-2", Phase: "Running"},
+	}
+	kube := &core.KubeMock{
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return nodes, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			for _, n := range nodes {
+				if n.Name == name {
+					return n, nil
+				}
+			}
+			return kubeclient.Node{}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			if node == "" {
+				return pods, nil
+			}
+			var result []kubeclient.Pod
+			for _, p := range pods {
+				if p.Node == node {
+					result = append(result, p)
+				}
+			}
+			return result, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given a node out of pod slots, then count the slots taken by running pods", func(t *testing.T) {
+		result, err := service.GetNodes(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 4, result[0].PodCount)
+		assert.Equal(t, int64(4), result[0].MaxPods)
+		assert.Equal(t, 100.0, result[0].PodSlotsPercent)
+		assert.Equal(t, "30Gi", result[0].EphemeralRequest)
+		assert.Equal(t, 75.0, result[0].EphemeralRequestPercent)
+		assert.Equal(t, 1, result[1].PodCount)
+	})
+
+	t.Run("given the modes, then size pods by ephemeral storage and pod slots", func(t *testing.T) {
+		result, err := service.GetPods(context.Background(), "node-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "25%", result[0].EphemeralSize)
+		assert.Equal(t, "10Gi", result[0].EphemeralRequest)
+		assert.Equal(t, "25%", result[0].SlotSize)
+		assert.Equal(t, "0%", result[2].SlotSize)
+		assert.Equal(t, "0%", result[2].EphemeralSize)
+		assert.Equal(t, "0%", result[3].EphemeralSize)
+	})
+
+	t.Run("given node groups, then total slots and ephemeral storage", func(t *testing.T) {
+		groups, _, err := service.GetNodeGroups(context.Background(), "", core.NodeQuery{})
+		assert.Nil(t, err)
+		assert.Equal(t, 5, groups[0].PodCount)
+		assert.Equal(t, int64(114), groups[0].MaxPods)
+		assert.Equal(t, "140Gi", groups[0].EphemeralStorage)
+		assert.Equal(t, "30Gi", groups[0].EphemeralRequest)
+	})
+
+	t.Run("given the nodes page, then show the pod slots of every node", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetNodes(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "| Pods: 4 of 4")
+		assert.Contains(t, rec.Body.String(), "| Ephemeral: 30Gi of 40Gi requested")
+	})
+}
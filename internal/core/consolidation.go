@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/jawahars16/hawk8s/internal/analysis"
+)
+
+var ErrNeedsAllNodes = errors.New("this report needs permission to list nodes")
+
+type (
+	consolidationViewModel struct {
+		Nodes       int
+		NodesNeeded int
+		Removable   []removableNode
+		Kept        []string
+		Before      analysis.Utilization
+		After       analysis.Utilization
+		Error       string
+	}
+	removableNode struct {
+		Name         string
+		InstanceType string
+		Moves        []drainRow
+		// Hidden counts the moved pods the user may not see.
+		Hidden int
+	}
+)
+
+// GetConsolidation reports how many nodes the current pods could be packed
+// on, by their requests, and which nodes could go.
+func (s *Service) GetConsolidation(ctx context.Context) (consolidationViewModel, error) {
+	access, err := s.access(ctx)
+	if err != nil {
+		return consolidationViewModel{}, err
+	}
+	if access != nil && !access.allNodes {
+		return consolidationViewModel{}, ErrNeedsAllNodes
+	}
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return consolidationViewModel{}, err
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return consolidationViewModel{}, err
+	}
+
+	result := analysis.Consolidate(nodes, pods)
+	vm := consolidationViewModel{
+		Nodes:       result.Nodes,
+		NodesNeeded: result.NodesNeeded,
+		Kept:        result.Kept,
+		Before:      result.Before,
+		After:       result.After,
+	}
+	for _, n := range result.Removable {
+		removable := removableNode{Name: n.Name, InstanceType: n.InstanceType}
+		for _, m := range n.Moves {
+			if !access.namespace(m.Pod.Namespace) {
+				removable.Hidden++
+				continue
+			}
+			removable.Moves = append(removable.Moves, drainRow{
+				Name:          m.Pod.Name,
+				Namespace:     m.Pod.Namespace,
+				Workload:      m.Pod.Workload,
+				Color:         namespaceByName(m.Pod.Namespace).Color,
+				CPURequest:    cpuMilliToHumanReadable(m.Pod.CPURequest),
+				MemoryRequest: memoryBytesToHumanReadable(m.Pod.MemoryRequest),
+				Node:          m.Node,
+			})
+		}
+		vm.Removable = append(vm.Removable, removable)
+	}
+	return vm, nil
+}
+
+func (h *Handler) GetConsolidation(w http.ResponseWriter, r *http.Request) {
+	vm, err := h.service.GetConsolidation(r.Context())
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "consolidation.html", vm)
+}
@@ -0,0 +1,57 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/auth"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Consolidation(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"team-a"}, nil
+		},
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{
+				{Name: "node1", AvailableCPU: 2000, AllocatableMemory: 4 << 30},
+				{Name: "node2", AvailableCPU: 2000, AllocatableMemory: 4 << 30, InstanceType: "m5.large"},
+			}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "web", Namespace: "team-a", Node: "node1", Workload: "Deployment/web", CPURequest: 1000},
+				{Name: "api", Namespace: "team-a", Node: "node2", Workload: "Deployment/api", CPURequest: 500},
+			}, nil
+		},
+	}
+
+	t.Run("given the report page, then list the removable nodes", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), core.NewService(kube))
+		req := httptest.NewRequest(http.MethodGet, "/reports/consolidation", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetConsolidation(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "The pods fit on 1 of 2 nodes")
+		assert.Contains(t, rec.Body.String(), "m5.large")
+		assert.Contains(t, rec.Body.String(), "team-a/api")
+		assert.Contains(t, rec.Body.String(), "75%")
+	})
+
+	t.Run("given a user who may not list nodes, then refuse the report", func(t *testing.T) {
+		authorizer := &core.AuthorizerMock{
+			CanListFunc: func(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+				return resource == "pods", nil
+			},
+		}
+		ctx := auth.WithIdentity(context.Background(), auth.Identity{Name: "alice"})
+		_, err := core.NewService(kube, core.WithAuthorization(authorizer, nil, nil)).GetConsolidation(ctx)
+		assert.ErrorIs(t, err, core.ErrNeedsAllNodes)
+	})
+}
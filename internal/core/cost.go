@@ -0,0 +1,346 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	GroupByNamespace = "namespace"
+	GroupByWorkload  = "workload"
+	GroupByLabel     = "label"
+)
+
+const gib = 1 << 30
+
+var ErrNoPricing = errors.New("no pricing configured, start hawk8s with --pricing-file")
+
+// Pricing is what a node costs per vCPU-hour and per GiB-hour of memory. The
+// first node price whose selector matches the node labels applies, Default
+// otherwise.
+type Pricing struct {
+	Currency string      `json:"currency"`
+	Default  Price       `json:"default"`
+	Nodes    []NodePrice `json:"nodes"`
+}
+
+type Price struct {
+	CPUHour       float64 `json:"cpuHour"`
+	MemoryGiBHour float64 `json:"memoryGiBHour"`
+}
+
+type NodePrice struct {
+	Selector map[string]string `json:"selector"`
+	Price
+}
+
+type (
+	costViewModel struct {
+		Currency string
+		GroupBy  string
+		Label    string
+		Rows     []costRow
+		Idle     costRow
+		Total    costRow
+		ShowIdle bool
+		Samples  int
+		// HistorySpan is the time the samples cover, which month-to-date is
+		// estimated from.
+		HistorySpan string
+		Error       string
+	}
+	costRow struct {
+		Name        string
+		Color       string
+		CPU         float64
+		Memory      float64
+		Hourly      float64
+		MonthToDate float64
+		Projected   float64
+	}
+	// allocation is the hourly cost of each group and the idle cost of the
+	// nodes at one point in time.
+	allocation struct {
+		groups map[string]*costRow
+		idle   costRow
+	}
+)
+
+func LoadPricing(path string) (*Pricing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pricing Pricing
+	if err := yaml.UnmarshalStrict(data, &pricing); err != nil {
+		return nil, err
+	}
+	if pricing.Currency == "" {
+		pricing.Currency = "USD"
+	}
+	return &pricing, nil
+}
+
+func WithPricing(pricing *Pricing) Option {
+	return func(s *Service) {
+		s.pricing = pricing
+	}
+}
+
+func (p *Pricing) price(labels map[string]string) Price {
+	for _, n := range p.Nodes {
+		if matchLabels(n.Selector, labels) {
+			return n.Price
+		}
+	}
+	return p.Default
+}
+
+func matchLabels(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// GetCosts allocates the cost of every node to its pods by the larger of their
+// request and usage, and sums it by namespace, workload or the value of a pod
+// label. What the pods do not use of a node is idle cost. Month-to-date and
+// projected costs are both estimates: they extrapolate the average hourly cost
+// of the usage history, which covers at most the history retention, or the
+// current cost without history.
+func (s *Service) GetCosts(ctx context.Context, groupBy string, label string) (costViewModel, error) {
+	if s.pricing == nil {
+		return costViewModel{}, ErrNoPricing
+	}
+	if groupBy != GroupByWorkload && groupBy != GroupByLabel {
+		groupBy = GroupByNamespace
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return costViewModel{}, err
+	}
+
+	now := s.now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	history, err := s.kube.GetHistory(ctx, monthStart)
+	if err != nil {
+		return costViewModel{}, err
+	}
+	current, err := s.currentSample(ctx)
+	if err != nil {
+		return costViewModel{}, err
+	}
+
+	vm := costViewModel{
+		Currency: s.pricing.Currency,
+		GroupBy:  groupBy,
+		Label:    label,
+		ShowIdle: access == nil || access.allNodes,
+		Samples:  len(history),
+	}
+	if len(history) > 0 {
+		vm.HistorySpan = age(history[len(history)-1].Time.Sub(history[0].Time))
+	}
+	if len(history) == 0 {
+		history = []kubeclient.Sample{current}
+	}
+
+	// Average the hourly cost over the samples, a group missing from a
+	// sample costing nothing at that time.
+	average := allocation{groups: make(map[string]*costRow)}
+	for _, sample := range history {
+		a := s.allocate(sample, access, groupBy, label)
+		for name, row := range a.groups {
+			avg, ok := average.groups[name]
+			if !ok {
+				avg = &costRow{Name: name}
+				average.groups[name] = avg
+			}
+			avg.Hourly += row.Hourly / float64(len(history))
+		}
+		average.idle.Hourly += a.idle.Hourly / float64(len(history))
+	}
+
+	elapsed := now.Sub(monthStart).Hours()
+	hours := monthStart.AddDate(0, 1, 0).Sub(monthStart).Hours()
+	project := func(row *costRow, avg costRow) {
+		row.MonthToDate = avg.Hourly * elapsed
+		row.Projected = avg.Hourly * hours
+	}
+
+	latest := s.allocate(current, access, groupBy, label)
+	for name, avg := range average.groups {
+		if _, ok := latest.groups[name]; !ok {
+			latest.groups[name] = &costRow{Name: name}
+		}
+		project(latest.groups[name], *avg)
+	}
+	for _, row := range latest.groups {
+		if groupBy == GroupByNamespace {
+			row.Color = namespaceByName(row.Name).Color
+		}
+		vm.Rows = append(vm.Rows, *row)
+		vm.Total.add(*row)
+	}
+	sort.Slice(vm.Rows, func(i, j int) bool {
+		if vm.Rows[i].Projected != vm.Rows[j].Projected {
+			return vm.Rows[i].Projected > vm.Rows[j].Projected
+		}
+		return vm.Rows[i].Name < vm.Rows[j].Name
+	})
+
+	if vm.ShowIdle {
+		vm.Idle = latest.idle
+		vm.Idle.Name = "Idle"
+		project(&vm.Idle, average.idle)
+		vm.Total.add(vm.Idle)
+	}
+	vm.Total.Name = "Total"
+	return vm, nil
+}
+
+func (r *costRow) add(other costRow) {
+	r.CPU += other.CPU
+	r.Memory += other.Memory
+	r.Hourly += other.Hourly
+	r.MonthToDate += other.MonthToDate
+	r.Projected += other.Projected
+}
+
+func (s *Service) currentSample(ctx context.Context) (kubeclient.Sample, error) {
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return kubeclient.Sample{}, err
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return kubeclient.Sample{}, err
+	}
+
+	sample := kubeclient.Sample{Time: s.now()}
+	for _, n := range nodes {
+		sample.Nodes = append(sample.Nodes, kubeclient.NodeSample{
+			Name:              n.Name,
+			Labels:            n.Labels,
+			TotalCPU:          n.TotalCPU,
+			TotalMemory:       n.TotalMemory,
+			AvailableCPU:      n.AvailableCPU,
+			AllocatableMemory: n.AllocatableMemory,
+		})
+	}
+	for _, p := range pods {
+		// Finished pods are no longer charged, like in the history.
+		if scheduler.Finished(p) {
+			continue
+		}
+		sample.Pods = append(sample.Pods, kubeclient.PodSample{
+			Name:          p.Name,
+			Namespace:     p.Namespace,
+			Node:          p.Node,
+			Workload:      p.Workload,
+			Labels:        p.Labels,
+			CPUUsage:      p.CPUUsage,
+			MemoryUsage:   p.MemoryUsage,
+			CPURequest:    p.CPURequest,
+			MemoryRequest: p.MemoryRequest,
+		})
+	}
+	return sample, nil
+}
+
+// allocate splits the hourly cost of each node between its pods. A node costs
+// its capacity, so the reserved part of it counts as idle. When the pods claim
+// more than the node has, their shares are scaled down to the node cost.
+func (s *Service) allocate(sample kubeclient.Sample, access *accessScope, groupBy string, label string) allocation {
+	podsByNode := make(map[string][]kubeclient.PodSample)
+	for _, p := range sample.Pods {
+		podsByNode[p.Node] = append(podsByNode[p.Node], p)
+	}
+
+	result := allocation{groups: make(map[string]*costRow)}
+	for _, n := range sample.Nodes {
+		price := s.pricing.price(n.Labels)
+		capacityCPU := n.TotalCPU
+		if capacityCPU == 0 {
+			capacityCPU = n.AvailableCPU
+		}
+		capacityMemory := n.TotalMemory
+		if capacityMemory == 0 {
+			capacityMemory = n.AllocatableMemory
+		}
+		nodeCPU := float64(capacityCPU) / 1000 * price.CPUHour
+		nodeMemory := float64(capacityMemory) / gib * price.MemoryGiBHour
+
+		var claimedCPU, claimedMemory int64
+		for _, p := range podsByNode[n.Name] {
+			claimedCPU += max(p.CPURequest, p.CPUUsage)
+			claimedMemory += max(p.MemoryRequest, p.MemoryUsage)
+		}
+		cpuScale, memoryScale := 1.0, 1.0
+		if claimedCPU > capacityCPU {
+			cpuScale = float64(capacityCPU) / float64(claimedCPU)
+		}
+		if claimedMemory > capacityMemory {
+			memoryScale = float64(capacityMemory) / float64(claimedMemory)
+		}
+
+		allocatedCPU, allocatedMemory := 0.0, 0.0
+		for _, p := range podsByNode[n.Name] {
+			cpu := float64(max(p.CPURequest, p.CPUUsage)) * cpuScale / 1000 * price.CPUHour
+			memory := float64(max(p.MemoryRequest, p.MemoryUsage)) * memoryScale / gib * price.MemoryGiBHour
+			allocatedCPU += cpu
+			allocatedMemory += memory
+			if !access.namespace(p.Namespace) {
+				continue
+			}
+
+			name := costGroup(p, groupBy, label)
+			row, ok := result.groups[name]
+			if !ok {
+				row = &costRow{Name: name}
+				result.groups[name] = row
+			}
+			row.CPU += cpu
+			row.Memory += memory
+			row.Hourly += cpu + memory
+		}
+
+		result.idle.CPU += nodeCPU - allocatedCPU
+		result.idle.Memory += nodeMemory - allocatedMemory
+		result.idle.Hourly += nodeCPU - allocatedCPU + nodeMemory - allocatedMemory
+	}
+	return result
+}
+
+func costGroup(p kubeclient.PodSample, groupBy string, label string) string {
+	switch groupBy {
+	case GroupByWorkload:
+		return p.Namespace + "/" + podWorkload(p.Workload, p.Name)
+	case GroupByLabel:
+		if value, ok := p.Labels[label]; ok {
+			return value
+		}
+		return "(none)"
+	default:
+		return p.Namespace
+	}
+}
+
+func (h *Handler) GetCosts(w http.ResponseWriter, r *http.Request) {
+	vm, err := h.service.GetCosts(r.Context(), r.URL.Query().Get("groupBy"), r.URL.Query().Get("label"))
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "costs.html", vm)
+}
@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Costs(t *testing.T) {
+	nodes := []kubeclient.Node{
+		{Name: "node1", TotalCPU: 4000, TotalMemory: 8 << 30, AvailableCPU: 3800, AllocatableMemory: 7 << 30},
+	}
+	pods := []kubeclient.Pod{
+		{Name: "web-1", Namespace: "team-a", Node: "node1", Workload: "Deployment/web", Labels: map[string]string{"team": "shop"},
+			CPURequest: 1000, CPUUsage: 500, MemoryRequest: 1 << 30},
+		{Name: "batch", Namespace: "team-b", Node: "node1",
+			CPUUsage: 2000, MemoryUsage: 2 << 30},
+		{Name: "migrate", Namespace: "team-c", Node: "node1", Phase: "Succeeded",
+			CPURequest: 1000, MemoryRequest: 1 << 30},
+	}
+	pricing := &core.Pricing{Currency: "EUR", Default: core.Price{CPUHour: 1, MemoryGiBHour: 1}}
+	newKube := func(history []kubeclient.Sample) *core.KubeMock {
+		return &core.KubeMock{
+			GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+				return nodes, nil
+			},
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				return pods, nil
+			},
+			GetHistoryFunc: func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+				return history, nil
+			},
+		}
+	}
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	hoursInMonth := monthStart.AddDate(0, 1, 0).Sub(monthStart).Hours()
+
+	t.Run("given no pricing, then return an error", func(t *testing.T) {
+		service := core.NewService(newKube(nil))
+		_, err := service.GetCosts(context.Background(), core.GroupByNamespace, "")
+		assert.ErrorIs(t, err, core.ErrNoPricing)
+	})
+
+	t.Run("given pods by namespace, then charge the larger of request and usage and keep the rest idle", func(t *testing.T) {
+		service := core.NewService(newKube(nil), core.WithPricing(pricing))
+		vm, err := service.GetCosts(context.Background(), core.GroupByNamespace, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "EUR", vm.Currency)
+		assert.Equal(t, 2, len(vm.Rows))
+		assert.Equal(t, "team-b", vm.Rows[0].Name)
+		assert.InDelta(t, 2.0, vm.Rows[0].CPU, 1e-9)
+		assert.InDelta(t, 4.0, vm.Rows[0].Hourly, 1e-9)
+		assert.Equal(t, "team-a", vm.Rows[1].Name)
+		assert.InDelta(t, 2.0, vm.Rows[1].Hourly, 1e-9)
+		assert.True(t, vm.ShowIdle)
+		assert.InDelta(t, 1.0, vm.Idle.CPU, 1e-9)
+		assert.InDelta(t, 5.0, vm.Idle.Memory, 1e-9)
+		assert.InDelta(t, 12.0, vm.Total.Hourly, 1e-9)
+		assert.InDelta(t, 4*hoursInMonth, vm.Rows[0].Projected, 1e-6)
+	})
+
+	t.Run("given workload and label grouping, then name groups after them", func(t *testing.T) {
+		service := core.NewService(newKube(nil), core.WithPricing(pricing))
+		vm, err := service.GetCosts(context.Background(), core.GroupByWorkload, "")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"team-b/Pod/batch", "team-a/Deployment/web"}, []string{vm.Rows[0].Name, vm.Rows[1].Name})
+
+		vm, err = service.GetCosts(context.Background(), core.GroupByLabel, "team")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"(none)", "shop"}, []string{vm.Rows[0].Name, vm.Rows[1].Name})
+	})
+
+	t.Run("given a node price matching the node labels, then use it", func(t *testing.T) {
+		spot := *pricing
+		spot.Nodes = []core.NodePrice{{Selector: map[string]string{"spot": "true"}, Price: core.Price{CPUHour: 0.5}}}
+		nodes[0].Labels = map[string]string{"spot": "true"}
+		defer func() { nodes[0].Labels = nil }()
+
+		service := core.NewService(newKube(nil), core.WithPricing(&spot))
+		vm, err := service.GetCosts(context.Background(), core.GroupByNamespace, "")
+		assert.Nil(t, err)
+		assert.InDelta(t, 2.0, vm.Total.Hourly, 1e-9)
+	})
+
+	t.Run("given usage history, then project the average hourly cost", func(t *testing.T) {
+		sample := func(ago time.Duration, cpu int64) kubeclient.Sample {
+			return kubeclient.Sample{
+				Time:  now.Add(-ago),
+				Nodes: []kubeclient.NodeSample{{Name: "node1", TotalCPU: 4000, TotalMemory: 8 << 30}},
+				Pods:  []kubeclient.PodSample{{Name: "web-1", Namespace: "team-a", Node: "node1", CPUUsage: cpu}},
+			}
+		}
+		service := core.NewService(newKube([]kubeclient.Sample{sample(3*time.Hour, 1000), sample(0, 3000)}), core.WithPricing(pricing))
+		vm, err := service.GetCosts(context.Background(), core.GroupByNamespace, "")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, vm.Samples)
+		assert.Equal(t, "3h", vm.HistorySpan)
+		for _, row := range vm.Rows {
+			if row.Name == "team-a" {
+				assert.InDelta(t, 2*hoursInMonth, row.Projected, 1e-6)
+			}
+		}
+		assert.InDelta(t, 10*hoursInMonth, vm.Idle.Projected, 1e-6)
+	})
+
+	t.Run("given an htmx request, then render the fragment, else the page loading it", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), core.NewService(newKube(nil), core.WithPricing(pricing)))
+
+		req := httptest.NewRequest(http.MethodGet, "/costs?groupBy=workload", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetCosts(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "team-a/Deployment/web")
+		assert.Contains(t, rec.Body.String(), "Month to date (est.)")
+		assert.NotContains(t, rec.Body.String(), "<html")
+
+		rec = httptest.NewRecorder()
+		handler.GetCosts(rec, httptest.NewRequest(http.MethodGet, "/costs?groupBy=workload", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `hx-get="/costs?groupBy=workload"`)
+	})
+
+	t.Run("given a pricing file, then load it", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pricing.yaml")
+		os.WriteFile(path, []byte(`
+default:
+  cpuHour: 0.03
+  memoryGiBHour: 0.004
+nodes:
+- selector:
+    node.kubernetes.io/instance-type: m5.large
+  cpuHour: 0.048
+  memoryGiBHour: 0.006
+`), 0o600)
+		pricing, err := core.LoadPricing(path)
+		assert.Nil(t, err)
+		assert.Equal(t, "USD", pricing.Currency)
+		assert.Equal(t, 0.03, pricing.Default.CPUHour)
+		assert.Equal(t, 0.048, pricing.Nodes[0].CPUHour)
+		assert.Equal(t, "m5.large", pricing.Nodes[0].Selector["node.kubernetes.io/instance-type"])
+	})
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+const (
+	// gpuSuffix ends the names of GPU resources, like nvidia.com/gpu and
+	// amd.com/gpu, which GPU mode adds up.
+	gpuSuffix = "/gpu"
+	// maxDeviceSlots is the most devices a node is drawn with one slot each.
+	maxDeviceSlots = 64
+	idleSlotColor  = "#E2E8F0"
+)
+
+type (
+	devicesViewModel struct {
+		// Resource is the extended resource shown, GPUs by default.
+		Resource    string
+		Resources   []viewLink
+		Nodes       []deviceNode
+		Allocatable int64
+		Allocated   int64
+		Idle        int64
+		// Pending are the visible unscheduled pods requesting the resource.
+		Pending []string
+		Error   string
+	}
+	deviceNode struct {
+		Name        string
+		Capacity    int64
+		Allocatable int64
+		Allocated   int64
+		Idle        int64
+		// Slots has a slot per device, allocated ones first, unless the node
+		// has more than maxDeviceSlots.
+		Slots []deviceSlot
+		Pods  []devicePod
+	}
+	deviceSlot struct {
+		Color string
+		Title string
+		Idle  bool
+	}
+	devicePod struct {
+		Name      string
+		Namespace string
+		Color     string
+		Request   int64
+		Limit     int64
+	}
+)
+
+// GetDevices shows how the devices of an extended resource are allocated to
+// the pods of each visible node, and how many are idle. Pods in namespaces
+// the user cannot see still hold devices, but are not named.
+func (s *Service) GetDevices(ctx context.Context, resource string) (devicesViewModel, error) {
+	var vm devicesViewModel
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return vm, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return vm, err
+	}
+	visibleNodes, err := s.visibleNodes(ctx, access)
+	if err != nil {
+		return vm, err
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return vm, err
+	}
+
+	resources := make(map[string]bool)
+	for _, n := range nodes {
+		if visibleNodes != nil && !visibleNodes[n.Name] {
+			continue
+		}
+		for name := range n.ExtendedCapacity {
+			resources[name] = true
+		}
+	}
+	names := sortedKeys(resources)
+	vm.Resource = resource
+	if vm.Resource == "" {
+		vm.Resource = defaultDevice(names)
+	}
+	for _, name := range names {
+		vm.Resources = append(vm.Resources, viewLink{
+			Name:   name,
+			Href:   "/devices?resource=" + url.QueryEscape(name),
+			Active: name == vm.Resource,
+		})
+	}
+	if vm.Resource == "" {
+		return vm, nil
+	}
+
+	podsByNode := make(map[string][]kubeclient.Pod)
+	for _, p := range pods {
+		if p.ExtendedRequests[vm.Resource] == 0 || scheduler.Finished(p) {
+			continue
+		}
+		if p.Node == "" {
+			if access.namespace(p.Namespace) {
+				vm.Pending = append(vm.Pending, p.Namespace+"/"+p.Name)
+			}
+			continue
+		}
+		podsByNode[p.Node] = append(podsByNode[p.Node], p)
+	}
+
+	for _, n := range nodes {
+		if visibleNodes != nil && !visibleNodes[n.Name] {
+			continue
+		}
+		if n.ExtendedCapacity[vm.Resource] == 0 && len(podsByNode[n.Name]) == 0 {
+			continue
+		}
+		node := deviceNode{
+			Name:        n.Name,
+			Capacity:    n.ExtendedCapacity[vm.Resource],
+			Allocatable: n.ExtendedAllocatable[vm.Resource],
+		}
+		for _, p := range podsByNode[n.Name] {
+			request := p.ExtendedRequests[vm.Resource]
+			node.Allocated += request
+			model := devicePod{Name: p.Name, Namespace: p.Namespace, Color: noLabelColor, Request: request, Limit: p.ExtendedLimits[vm.Resource]}
+			title := "a pod in another namespace"
+			if access.namespace(p.Namespace) {
+				model.Color = namespaceByName(p.Namespace).Color
+				title = p.Namespace + "/" + p.Name
+				node.Pods = append(node.Pods, model)
+			}
+			for i := int64(0); i < request; i++ {
+				node.Slots = append(node.Slots, deviceSlot{Color: model.Color, Title: title})
+			}
+		}
+		node.Idle = max(node.Allocatable-node.Allocated, 0)
+		for i := int64(0); i < node.Idle; i++ {
+			node.Slots = append(node.Slots, deviceSlot{Color: idleSlotColor, Title: "idle", Idle: true})
+		}
+		if len(node.Slots) > maxDeviceSlots {
+			node.Slots = nil
+		}
+		vm.Nodes = append(vm.Nodes, node)
+		vm.Allocatable += node.Allocatable
+		vm.Allocated += node.Allocated
+		vm.Idle += node.Idle
+	}
+	// Nodes with the most idle devices first, where pods would fit.
+	sort.SliceStable(vm.Nodes, func(i, j int) bool {
+		if vm.Nodes[i].Idle != vm.Nodes[j].Idle {
+			return vm.Nodes[i].Idle > vm.Nodes[j].Idle
+		}
+		return vm.Nodes[i].Name < vm.Nodes[j].Name
+	})
+	sort.Strings(vm.Pending)
+	return vm, nil
+}
+
+// defaultDevice picks the first GPU resource, or else the first resource.
+func defaultDevice(names []string) string {
+	for _, name := range names {
+		if strings.HasSuffix(name, gpuSuffix) {
+			return name
+		}
+	}
+	if len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// gpus adds up the GPU resources of the list.
+func gpus(resources map[string]int64) int64 {
+	var total int64
+	for name, value := range resources {
+		if strings.HasSuffix(name, gpuSuffix) {
+			total += value
+		}
+	}
+	return total
+}
+
+func gpuSize(p kubeclient.Pod, n kubeclient.Node) string {
+	requested, allocatable := gpus(p.ExtendedRequests), gpus(n.ExtendedAllocatable)
+	if requested == 0 || allocatable == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%v%%", float32(percent(requested, allocatable)))
+}
+
+func (h *Handler) GetDevices(w http.ResponseWriter, r *http.Request) {
+	vm, err := h.service.GetDevices(r.Context(), r.URL.Query().Get("resource"))
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "devices.html", vm)
+}
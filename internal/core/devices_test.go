@@ -0,0 +1,111 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Devices(t *testing.T) {
+	gpu := func(n int64) map[string]int64 { return map[string]int64{"nvidia.com/gpu": n} }
+	nodes := []kubeclient.Node{
+		{Name: "cpu-1", AvailableCPU: 4000, AllocatableMemory: 16 << 30},
+		{Name: "gpu-1", AvailableCPU: 8000, AllocatableMemory: 32 << 30, ExtendedCapacity: gpu(4), ExtendedAllocatable: gpu(4)},
+		{Name: "gpu-2", AvailableCPU: 8000, AllocatableMemory: 32 << 30, ExtendedCapacity: gpu(4), ExtendedAllocatable: gpu(4)},
+		{Name: "fpga-1", ExtendedCapacity: map[string]int64{"xilinx.com/fpga": 2}, ExtendedAllocatable: map[string]int64{"xilinx.com/fpga": 2}},
+	}
+	pods := []kubeclient.Pod{
+		{Name: "train", Namespace: "ml", Node: "gpu-1", Phase: "Running", ExtendedRequests: gpu(3), ExtendedLimits: gpu(3)},
+		{Name: "serve", Namespace: "ml", Node: "gpu-2", Phase: "Running", ExtendedRequests: gpu(1), ExtendedLimits: gpu(1)},
+		{Name: "done", Namespace: "ml", Node: "gpu-2", Phase: "Succeeded", ExtendedRequests: gpu(2), ExtendedLimits: gpu(2)},
+		{Name: "queued", Namespace: "ml", Phase: "Pending", ExtendedRequests: gpu(8), ExtendedLimits: gpu(8)},
+		{Name: "web", Namespace: "shop", Node: "cpu-1", Phase: "Running"},
+	}
+	kube := &core.KubeMock{
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return nodes, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			for _, n := range nodes {
+				if n.Name == name {
+					return n, nil
+				}
+			}
+			return kubeclient.Node{}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			if node == "" {
+				return pods, nil
+			}
+			var result []kubeclient.Pod
+			for _, p := range pods {
+				if p.Node == node {
+					result = append(result, p)
+				}
+			}
+			return result, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given GPU nodes, then show allocated and idle GPUs, most idle first", func(t *testing.T) {
+		vm, err := service.GetDevices(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "nvidia.com/gpu", vm.Resource)
+		assert.Equal(t, 2, len(vm.Resources))
+		assert.Equal(t, int64(8), vm.Allocatable)
+		assert.Equal(t, int64(4), vm.Allocated)
+		assert.Equal(t, int64(4), vm.Idle)
+		assert.Equal(t, []string{"ml/queued"}, vm.Pending)
+
+		assert.Equal(t, 2, len(vm.Nodes))
+		assert.Equal(t, "gpu-2", vm.Nodes[0].Name)
+		assert.Equal(t, int64(3), vm.Nodes[0].Idle)
+		assert.Equal(t, "gpu-1", vm.Nodes[1].Name)
+		assert.Equal(t, int64(3), vm.Nodes[1].Allocated)
+		assert.Equal(t, int64(1), vm.Nodes[1].Idle)
+		assert.Equal(t, 4, len(vm.Nodes[1].Slots))
+		assert.Equal(t, "ml/train", vm.Nodes[1].Slots[0].Title)
+		assert.True(t, vm.Nodes[1].Slots[3].Idle)
+	})
+
+	t.Run("given another extended resource, then show it instead", func(t *testing.T) {
+		vm, err := service.GetDevices(context.Background(), "xilinx.com/fpga")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(vm.Nodes))
+		assert.Equal(t, "fpga-1", vm.Nodes[0].Name)
+		assert.Equal(t, int64(2), vm.Idle)
+		assert.Empty(t, vm.Pending)
+	})
+
+	t.Run("given GPU mode, then size pods by their share of the node's GPUs", func(t *testing.T) {
+		result, err := service.GetPods(context.Background(), "gpu-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "75%", result[0].GPUSize)
+		assert.Equal(t, int64(3), result[0].GPUs)
+
+		result, err = service.GetPods(context.Background(), "cpu-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "0%", result[0].GPUSize)
+	})
+
+	t.Run("given the devices page, then render a slot per device", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		req := httptest.NewRequest(http.MethodGet, "/devices?resource=nvidia.com%2Fgpu", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetDevices(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "4 of 8 allocated")
+		assert.Contains(t, rec.Body.String(), `title="ml/train"`)
+		assert.Contains(t, rec.Body.String(), "1 pending pods")
+	})
+}
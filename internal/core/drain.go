@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+type (
+	drainViewModel struct {
+		Node          string
+		Fits          bool
+		Placements    []drainRow
+		Unschedulable []drainRow
+		Ignored       []drainRow
+		// Hidden counts the pods on the node the user may not see.
+		Hidden int
+		Error  string
+	}
+	drainRow struct {
+		Name             string
+		Namespace        string
+		Workload         string
+		Color            string
+		CPURequest       string
+		MemoryRequest    string
+		Node             string
+		Reason           string
+		DisruptionBudget string
+	}
+)
+
+// SimulateDrain works out where the pods of the node would go if it were
+// drained, from the requests, scheduling constraints and disruption budgets in
+// the store.
+func (s *Service) SimulateDrain(ctx context.Context, name string) (drainViewModel, error) {
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return drainViewModel{}, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return drainViewModel{}, err
+	}
+	visibleNodes, err := s.visibleNodes(ctx, access)
+	if err != nil {
+		return drainViewModel{}, err
+	}
+	if visibleNodes != nil && !visibleNodes[name] {
+		return drainViewModel{}, fmt.Errorf("node %q not found", name)
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return drainViewModel{}, err
+	}
+	budgets, err := s.kube.GetDisruptionBudgets(ctx)
+	if err != nil && budgets == nil {
+		return drainViewModel{}, err
+	}
+
+	found := false
+	for _, n := range nodes {
+		found = found || n.Name == name
+	}
+	if !found {
+		return drainViewModel{}, fmt.Errorf("node %q not found", name)
+	}
+
+	result := scheduler.Drain(name, nodes, pods, budgets)
+	vm := drainViewModel{Node: name, Fits: len(result.Unschedulable) == 0}
+	rows := func(placements []scheduler.Placement) []drainRow {
+		var rows []drainRow
+		for _, p := range placements {
+			if !access.namespace(p.Pod.Namespace) {
+				vm.Hidden++
+				continue
+			}
+			rows = append(rows, drainRow{
+				Name:             p.Pod.Name,
+				Namespace:        p.Pod.Namespace,
+				Workload:         p.Pod.Workload,
+				Color:            namespaceByName(p.Pod.Namespace).Color,
+				CPURequest:       cpuMilliToHumanReadable(p.Pod.CPURequest),
+				MemoryRequest:    memoryBytesToHumanReadable(p.Pod.MemoryRequest),
+				Node:             p.Node,
+				Reason:           p.Reason,
+				DisruptionBudget: p.DisruptionBudget,
+			})
+		}
+		return rows
+	}
+	vm.Placements = rows(result.Placements)
+	vm.Unschedulable = rows(result.Unschedulable)
+	vm.Ignored = rows(result.Ignored)
+	return vm, nil
+}
+
+func (h *Handler) GetDrain(w http.ResponseWriter, r *http.Request) {
+	vm, err := h.service.SimulateDrain(r.Context(), r.URL.Query().Get("node"))
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "drain.html", vm)
+}
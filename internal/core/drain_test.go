@@ -0,0 +1,78 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/auth"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Drain(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"team-a", "team-b"}, nil
+		},
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{
+				{Name: "node1", AvailableCPU: 2000, AllocatableMemory: 4 << 30},
+				{Name: "node2", AvailableCPU: 1000, AllocatableMemory: 4 << 30},
+			}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "web", Namespace: "team-a", Node: "node1", Workload: "Deployment/web", CPURequest: 500},
+				{Name: "batch", Namespace: "team-b", Node: "node1", Workload: "Job/batch", CPURequest: 1500},
+			}, nil
+		},
+		GetDisruptionBudgetsFunc: func(ctx context.Context) ([]kubeclient.DisruptionBudget, error) {
+			return nil, nil
+		},
+	}
+
+	t.Run("given a node, then place its pods on the other nodes", func(t *testing.T) {
+		vm, err := core.NewService(kube).SimulateDrain(context.Background(), "node1")
+		assert.Nil(t, err)
+		assert.False(t, vm.Fits)
+		assert.Equal(t, 1, len(vm.Placements))
+		assert.Equal(t, "web", vm.Placements[0].Name)
+		assert.Equal(t, "node2", vm.Placements[0].Node)
+		assert.Equal(t, 1, len(vm.Unschedulable))
+		assert.Equal(t, "batch", vm.Unschedulable[0].Name)
+	})
+
+	t.Run("given an unknown node, then return an error", func(t *testing.T) {
+		_, err := core.NewService(kube).SimulateDrain(context.Background(), "node9")
+		assert.EqualError(t, err, `node "node9" not found`)
+	})
+
+	t.Run("given a user limited to a namespace, then hide the other pods", func(t *testing.T) {
+		authorizer := &core.AuthorizerMock{
+			CanListFunc: func(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+				return resource == "pods" && namespace == "team-a", nil
+			},
+		}
+		ctx := auth.WithIdentity(context.Background(), auth.Identity{Name: "alice"})
+		vm, err := core.NewService(kube, core.WithAuthorization(authorizer, nil, nil)).SimulateDrain(ctx, "node1")
+		assert.Nil(t, err)
+		assert.False(t, vm.Fits)
+		assert.Equal(t, 1, len(vm.Placements))
+		assert.Empty(t, vm.Unschedulable)
+		assert.Equal(t, 1, vm.Hidden)
+	})
+
+	t.Run("given the drain page, then render the placements", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), core.NewService(kube))
+		req := httptest.NewRequest(http.MethodGet, "/drain?node=node1", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetDrain(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Simulated drain of node1")
+		assert.Contains(t, rec.Body.String(), "Insufficient cpu")
+	})
+}
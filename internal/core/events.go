@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+)
+
+// warningReasons are the events marked on the pod slices.
+var warningReasons = map[string]bool{
+	"OOMKilled":   true,
+	"OOMKilling":  true,
+	"BackOff":     true,
+	"FailedMount": true,
+	"Evicted":     true,
+}
+
+type (
+	eventViewModel struct {
+		Events     []event
+		Filter     EventFilter
+		Namespaces []string
+		Kinds      []string
+		Reasons    []string
+		Error      string
+	}
+	event struct {
+		Type      string
+		Reason    string
+		Message   string
+		Kind      string
+		Namespace string
+		Name      string
+		Color     string
+		Source    string
+		Count     int32
+		Last      string
+		Age       string
+	}
+	// EventFilter narrows the event feed. Empty fields match everything,
+	// Search matches the object name and the message.
+	EventFilter struct {
+		Type      string
+		Namespace string
+		Kind      string
+		Name      string
+		Reason    string
+		Search    string
+	}
+)
+
+func (f EventFilter) matches(e kubeclient.Event) bool {
+	search := strings.ToLower(f.Search)
+	return (f.Type == "" || e.Type == f.Type) &&
+		(f.Namespace == "" || e.Object.Namespace == f.Namespace) &&
+		(f.Kind == "" || e.Object.Kind == f.Kind) &&
+		(f.Name == "" || e.Object.Name == f.Name) &&
+		(f.Reason == "" || e.Reason == f.Reason) &&
+		(search == "" || strings.Contains(strings.ToLower(e.Object.Name), search) ||
+			strings.Contains(strings.ToLower(e.Message), search))
+}
+
+// GetEvents returns the events the user may see matching the filter, newest
+// first, with the values the feed can be filtered on.
+func (s *Service) GetEvents(ctx context.Context, filter EventFilter) (eventViewModel, error) {
+	events, err := s.kube.GetEvents(ctx)
+	if err != nil && events == nil {
+		return eventViewModel{}, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return eventViewModel{}, err
+	}
+
+	vm := eventViewModel{Filter: filter}
+	namespaces, kinds, reasons := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	now := s.now()
+	for _, e := range events {
+		if !eventVisible(access, e) {
+			continue
+		}
+		if e.Object.Namespace != "" {
+			namespaces[e.Object.Namespace] = true
+		}
+		kinds[e.Object.Kind] = true
+		reasons[e.Reason] = true
+		if !filter.matches(e) {
+			continue
+		}
+		vm.Events = append(vm.Events, event{
+			Type:      e.Type,
+			Reason:    e.Reason,
+			Message:   e.Message,
+			Kind:      e.Object.Kind,
+			Namespace: e.Object.Namespace,
+			Name:      e.Object.Name,
+			Color:     namespaceByName(e.Object.Namespace).Color,
+			Source:    e.Source,
+			Count:     e.Count,
+			Last:      e.Last.Format(time.RFC3339),
+			Age:       age(now.Sub(e.Last)),
+		})
+	}
+	vm.Namespaces = sortedKeys(namespaces)
+	vm.Kinds = sortedKeys(kinds)
+	vm.Reasons = sortedKeys(reasons)
+	return vm, nil
+}
+
+// eventVisible shows events about cluster-scoped objects, like nodes, only to
+// users who may list nodes.
+func eventVisible(access *accessScope, e kubeclient.Event) bool {
+	if e.Object.Namespace == "" {
+		return access == nil || access.allNodes
+	}
+	return access.namespace(e.Object.Namespace)
+}
+
+// podWarnings groups the events in warningReasons by pod.
+func podWarnings(events []kubeclient.Event) map[kubeclient.ObjectReference][]string {
+	warnings := make(map[kubeclient.ObjectReference][]string)
+	for _, e := range events {
+		if e.Object.Kind != "Pod" || !warningReasons[e.Reason] {
+			continue
+		}
+		warnings[e.Object] = append(warnings[e.Object], fmt.Sprintf("%s: %s", e.Reason, e.Message))
+	}
+	return warnings
+}
+
+func age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	vm, err := h.service.GetEvents(r.Context(), EventFilter{
+		Type:      query.Get("type"),
+		Namespace: query.Get("namespace"),
+		Kind:      query.Get("kind"),
+		Name:      query.Get("name"),
+		Reason:    query.Get("reason"),
+		Search:    query.Get("q"),
+	})
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "events.html", vm)
+}
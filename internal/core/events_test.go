@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/auth"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Events(t *testing.T) {
+	now := time.Now()
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"team-a", "team-b"}, nil
+		},
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{{Name: "node1"}}, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return []kubeclient.Event{
+				{UID: "1", Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container", Count: 4, Last: now.Add(-time.Minute),
+					Object: kubeclient.ObjectReference{Kind: "Pod", Namespace: "team-a", Name: "web"}},
+				{UID: "2", Type: "Normal", Reason: "Pulled", Message: "Container image pulled", Count: 1, Last: now.Add(-2 * time.Minute),
+					Object: kubeclient.ObjectReference{Kind: "Pod", Namespace: "team-b", Name: "batch"}},
+				{UID: "3", Type: "Warning", Reason: "NodeNotReady", Message: "Node is not ready", Count: 1, Last: now.Add(-3 * time.Minute),
+					Object: kubeclient.ObjectReference{Kind: "Node", Name: "node1"}},
+			}, nil
+		},
+	}
+
+	t.Run("given no filter, then list all events with the filter values", func(t *testing.T) {
+		vm, err := core.NewService(kube).GetEvents(context.Background(), core.EventFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(vm.Events))
+		assert.Equal(t, []string{"team-a", "team-b"}, vm.Namespaces)
+		assert.Equal(t, []string{"Node", "Pod"}, vm.Kinds)
+		assert.Equal(t, []string{"BackOff", "NodeNotReady", "Pulled"}, vm.Reasons)
+		assert.Equal(t, "1m", vm.Events[0].Age)
+	})
+
+	t.Run("given a filter, then only list the matching events", func(t *testing.T) {
+		service := core.NewService(kube)
+		vm, err := service.GetEvents(context.Background(), core.EventFilter{Type: "Warning", Kind: "Pod"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(vm.Events))
+		assert.Equal(t, "web", vm.Events[0].Name)
+
+		vm, err = service.GetEvents(context.Background(), core.EventFilter{Search: "IMAGE"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(vm.Events))
+		assert.Equal(t, "batch", vm.Events[0].Name)
+	})
+
+	t.Run("given a user limited to a namespace, then hide the other events", func(t *testing.T) {
+		authorizer := &core.AuthorizerMock{
+			CanListFunc: func(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+				return resource == "pods" && namespace == "team-a", nil
+			},
+		}
+		ctx := auth.WithIdentity(context.Background(), auth.Identity{Name: "alice"})
+		vm, err := core.NewService(kube, core.WithAuthorization(authorizer, nil, nil)).GetEvents(ctx, core.EventFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(vm.Events))
+		assert.Equal(t, "web", vm.Events[0].Name)
+	})
+
+	t.Run("given the events page, then render the timeline of the object", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), core.NewService(kube))
+		req := httptest.NewRequest(http.MethodGet, "/events?kind=Pod&namespace=team-a&name=web", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetEvents(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Timeline of Pod team-a/web")
+		assert.Contains(t, rec.Body.String(), "Back-off restarting failed container")
+		assert.NotContains(t, rec.Body.String(), "Container image pulled")
+	})
+}
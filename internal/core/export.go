@@ -0,0 +1,248 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	SVG  = "svg"
+	HTML = "html"
+)
+
+const (
+	exportWidth      = 1000
+	exportMargin     = 20
+	exportLegendRow  = 20
+	exportNodeHeight = 56
+	exportBarHeight  = 28
+)
+
+type (
+	exportViewModel struct {
+		Mode       string
+		Namespace  string
+		Time       string
+		Width      int
+		Height     int
+		BarWidth   int
+		NodesY     int
+		Legend     []legendEntry
+		Nodes      []exportNode
+		Namespaces int
+	}
+	legendEntry struct {
+		Name   string
+		Color  string
+		Total  string
+		Pods   int
+		X      int
+		Y      int
+		Dimmed bool
+	}
+	exportNode struct {
+		Name  string
+		Info  string
+		Total string
+		Share string
+		Y     int
+		BarY  int
+		Pods  []exportPod
+	}
+	exportPod struct {
+		pod
+		X      float64
+		Width  float64
+		Share  float64
+		Usage  string
+		Fill   string
+		Dimmed bool
+	}
+)
+
+// Exporter renders the nodes view into a self-contained SVG image or HTML
+// page, for pasting into documents where the live app is not available.
+type Exporter struct {
+	tmpl    *template.Template
+	service service
+}
+
+func NewExporter(tmpl *template.Template, service service) *Exporter {
+	return &Exporter{
+		tmpl:    tmpl,
+		service: service,
+	}
+}
+
+func (e *Exporter) GetExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != HTML {
+		format = SVG
+	}
+	name := fmt.Sprintf("hawk8s-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	if format == SVG {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+
+	err := e.Write(r.Context(), w, format, r.URL.Query().Get("mode"), r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Write renders the current nodes view as SVG or HTML. Pods outside namespace
+// are grayed out, like in the UI, unless namespace is "all".
+func (e *Exporter) Write(ctx context.Context, w io.Writer, format string, mode string, namespace string) error {
+	vm, err := e.viewModel(ctx, mode, namespace)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case SVG:
+		return e.tmpl.ExecuteTemplate(w, "export.svg", vm)
+	case HTML:
+		return e.tmpl.ExecuteTemplate(w, "export.html", vm)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+func (e *Exporter) viewModel(ctx context.Context, mode string, namespace string) (exportViewModel, error) {
+	if mode != Memory {
+		mode = CPU
+	}
+	if namespace == "" {
+		namespace = "all"
+	}
+	nodes, err := e.service.GetNodes(ctx)
+	if err != nil {
+		return exportViewModel{}, err
+	}
+
+	vm := exportViewModel{
+		Mode:      mode,
+		Namespace: namespace,
+		Time:      time.Now().UTC().Format("2006-01-02 15:04:05 MST"),
+		Width:     exportWidth,
+		BarWidth:  exportWidth - 2*exportMargin,
+	}
+	legend := make(map[string]*legendEntry)
+	totals := make(map[string]int64)
+	for _, n := range nodes {
+		pods, err := e.service.GetPods(ctx, n.Name)
+		if err != nil {
+			return exportViewModel{}, err
+		}
+
+		en := exportNode{Name: n.Name, Info: n.Info}
+		var used float64
+		var usage, allocatable int64
+		for _, p := range pods {
+			share := float64(p.CpuShare)
+			podUsage := p.CpuMillis
+			usageText := p.CpuUsage
+			if mode == Memory {
+				share = float64(p.MemoryShare)
+				podUsage = p.MemoryBytes
+				usageText = p.MemoryUsage
+			}
+			// Pods are drawn at least 0.5% wide, which must not push the
+			// last ones past the end of the bar.
+			share = max(min(share, 100-used), 0)
+			dimmed := namespace != "all" && namespace != p.Namespace
+			fill := p.Color
+			if dimmed {
+				fill = GrayHex(p.Color)
+			}
+			en.Pods = append(en.Pods, exportPod{
+				pod:    p,
+				X:      used * float64(vm.BarWidth) / 100,
+				Width:  share * float64(vm.BarWidth) / 100,
+				Share:  share,
+				Usage:  usageText,
+				Fill:   fill,
+				Dimmed: dimmed,
+			})
+			used += share
+			usage += podUsage
+
+			entry, ok := legend[p.Namespace]
+			if !ok {
+				entry = &legendEntry{Name: p.Namespace, Color: p.Color, Dimmed: dimmed}
+				legend[p.Namespace] = entry
+			}
+			entry.Pods++
+			totals[p.Namespace] += podUsage
+		}
+
+		allocatable = n.CpuMillis
+		en.Total = fmt.Sprintf("%s of %s CPU", cpuCores(usage), cpuCores(allocatable))
+		if mode == Memory {
+			allocatable = n.MemoryBytes
+			en.Total = fmt.Sprintf("%s of %s memory", memoryBytesToHumanReadable(usage), memoryBytesToHumanReadable(allocatable))
+		}
+		en.Share = fmt.Sprintf("%.0f%%", percent(usage, allocatable))
+		vm.Nodes = append(vm.Nodes, en)
+	}
+
+	names := make([]string, 0, len(legend))
+	for name := range legend {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		entry := legend[name]
+		entry.Total = cpuCores(totals[name])
+		if mode == Memory {
+			entry.Total = memoryBytesToHumanReadable(totals[name])
+		}
+		entry.X = exportMargin + (i%4)*(vm.BarWidth/4)
+		entry.Y = 70 + (i/4)*exportLegendRow
+		vm.Legend = append(vm.Legend, *entry)
+	}
+	vm.Namespaces = len(names)
+
+	vm.NodesY = 70 + ((len(names)+3)/4)*exportLegendRow + exportMargin
+	for i := range vm.Nodes {
+		vm.Nodes[i].Y = vm.NodesY + i*exportNodeHeight
+		vm.Nodes[i].BarY = vm.Nodes[i].Y + 8
+	}
+	vm.Height = vm.NodesY + len(vm.Nodes)*exportNodeHeight + exportMargin
+	return vm, nil
+}
+
+// cpuCores formats millicores like cpuMilliToHumanReadable, but keeps a
+// decimal place of the cores, as totals of whole cores hide too much.
+func cpuCores(millis int64) string {
+	if millis < 1000 {
+		return fmt.Sprintf("%dm", millis)
+	}
+	return strconv.FormatFloat(math.Round(float64(millis)/100)/10, 'f', -1, 64)
+}
+
+// GrayHex converts a #RRGGBB color to the gray of the same luminance, like
+// the grayscale class does for pods outside the active namespace.
+func GrayHex(color string) string {
+	if len(color) != 7 || color[0] != '#' {
+		return color
+	}
+	rgb, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+		return color
+	}
+	r, g, b := float64(rgb>>16&0xff), float64(rgb>>8&0xff), float64(rgb&0xff)
+	l := int(0.299*r + 0.587*g + 0.114*b)
+	return fmt.Sprintf("#%02X%02X%02X", l, l, l)
+}
@@ -0,0 +1,138 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseTemplates(t *testing.T) *template.Template {
+	tmpl, err := template.New("").Funcs(sprig.FuncMap()).ParseGlob("../templates/*.html")
+	assert.Nil(t, err)
+	tmpl, err = tmpl.ParseGlob("../templates/*.svg")
+	assert.Nil(t, err)
+	return tmpl
+}
+
+func Test_Export(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"team-a", "team-b"}, nil
+		},
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{{Name: "node1", AvailableCPU: 2000, AllocatableMemory: 4 << 30}}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "pod-a", Namespace: "team-a", Node: node, CPUUsage: 1000, MemoryUsage: 1 << 30},
+				{Name: "pod-b", Namespace: "team-b", Node: node, CPUUsage: 500, MemoryUsage: 1 << 30},
+			}, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			return kubeclient.Node{Name: name, AvailableCPU: 2000, AllocatableMemory: 4 << 30}, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+	}
+	exporter := core.NewExporter(parseTemplates(t), core.NewService(kube))
+
+	t.Run("given svg format, then render a well-formed image with legend and totals", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Nil(t, exporter.Write(context.Background(), &out, core.SVG, core.CPU, "all"))
+
+		decoder := xml.NewDecoder(bytes.NewReader(out.Bytes()))
+		var widths []string
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			if el, ok := token.(xml.StartElement); ok && el.Name.Local == "rect" {
+				for _, attr := range el.Attr {
+					if attr.Name.Local == "width" {
+						widths = append(widths, attr.Value)
+					}
+				}
+			}
+		}
+		// Background, two legend swatches, the node bar and the pod slices.
+		assert.Equal(t, []string{"100%", "12", "12", "960", "480.00", "240.00"}, widths)
+		assert.Contains(t, out.String(), "team-a (1 pods, 1)")
+		assert.Contains(t, out.String(), "1.5 of 2 CPU (75%)")
+	})
+
+	t.Run("given more tiny pods than fit at their minimum width, then keep them within the bar", func(t *testing.T) {
+		var pods []kubeclient.Pod
+		for i := 0; i < 250; i++ {
+			pods = append(pods, kubeclient.Pod{Name: fmt.Sprintf("pod-%d", i), Namespace: "team-a", CPUUsage: 1})
+		}
+		kube := &core.KubeMock{
+			GetNodesFunc: kube.GetNodesFunc,
+			GetNodeFunc:  kube.GetNodeFunc,
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				return pods, nil
+			},
+			GetEventsFunc: kube.GetEventsFunc,
+		}
+		var out bytes.Buffer
+		exporter := core.NewExporter(parseTemplates(t), core.NewService(kube))
+		assert.Nil(t, exporter.Write(context.Background(), &out, core.SVG, core.CPU, "all"))
+
+		decoder := xml.NewDecoder(bytes.NewReader(out.Bytes()))
+		end := 0.0
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if el, ok := token.(xml.StartElement); ok && el.Name.Local == "rect" {
+				var x, width float64
+				for _, attr := range el.Attr {
+					switch attr.Name.Local {
+					case "x":
+						x, _ = strconv.ParseFloat(attr.Value, 64)
+					case "width":
+						width, _ = strconv.ParseFloat(attr.Value, 64)
+					}
+				}
+				if x > 0 {
+					end = max(end, x+width)
+				}
+			}
+		}
+		assert.InDelta(t, 960, end, 0.01)
+	})
+
+	t.Run("given html format and a namespace, then gray out other namespaces", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Nil(t, exporter.Write(context.Background(), &out, core.HTML, core.Memory, "team-a"))
+		html := out.String()
+		assert.Contains(t, html, "Namespace: team-a")
+		assert.Contains(t, html, "2Gi of 4Gi memory (50%)")
+		assert.Equal(t, 1, strings.Count(html, `<div class="dimmed">`))
+		assert.NotContains(t, html, "cdn.")
+	})
+
+	t.Run("given an export request, then serve svg as a download", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		exporter.GetExport(rec, httptest.NewRequest(http.MethodGet, "/export?format=svg&download=1", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/svg+xml", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Content-Disposition"), ".svg")
+	})
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+)
+
+const (
+	// noLabel groups the pods without the label pods are colored by.
+	noLabel      = "(none)"
+	noLabelColor = "#9E9E9E"
+)
+
+// generatedLabels are set by controllers on every pod and would only clutter
+// the label keys to group by.
+var generatedLabels = map[string]bool{
+	"pod-template-hash":        true,
+	"controller-revision-hash": true,
+	"pod-template-generation":  true,
+}
+
+// annotationPrefix marks the keys to group by that are annotations, not
+// labels. Label keys cannot contain a colon.
+const annotationPrefix = "annotation:"
+
+type (
+	filterViewModel struct {
+		// Label is the key pods are grouped by, empty for namespaces. It is
+		// an annotation key with annotationPrefix.
+		Label       string
+		Labels      []string
+		Annotations []string
+		Groups      []group
+	}
+	group struct {
+		Name        string
+		Color       string
+		Pods        int
+		CPUUsage    string
+		MemoryUsage string
+		// StorageRequest is the storage claimed by the pods.
+		StorageRequest string
+		// GPUs is the number of GPUs the pods request.
+		GPUs int64
+		// EphemeralRequest is the ephemeral storage the pods request.
+		EphemeralRequest string
+	}
+)
+
+// GetFilter lists the groups pods are colored by, namespaces or the values of
+// the label or annotation, with the number of pods and their usage.
+func (s *Service) GetFilter(ctx context.Context, label string) (filterViewModel, error) {
+	vm := filterViewModel{Label: label}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return vm, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return vm, err
+	}
+
+	type totals struct {
+		pods                                  int
+		cpu, memory, storage, gpus, ephemeral int64
+	}
+	groups := make(map[string]*totals)
+	if label == "" {
+		// Namespaces without pods are listed too, like the namespace filter.
+		namespaces, err := s.kube.GetNamespaces(ctx)
+		if err != nil {
+			return vm, err
+		}
+		for _, ns := range namespaces {
+			if access.namespace(ns) {
+				groups[ns] = &totals{}
+			}
+		}
+	}
+	keys := make(map[string]bool)
+	annotations := make(map[string]bool)
+	for _, p := range pods {
+		if !access.namespace(p.Namespace) {
+			continue
+		}
+		for k := range p.Labels {
+			if !generatedLabels[k] {
+				keys[k] = true
+			}
+		}
+		for k := range p.Annotations {
+			annotations[k] = true
+		}
+		name := p.Namespace
+		if label != "" {
+			name = podGroup(p, label)
+		}
+		t, ok := groups[name]
+		if !ok {
+			t = &totals{}
+			groups[name] = t
+		}
+		t.pods++
+		t.cpu += p.CPUUsage
+		t.memory += p.MemoryUsage
+		t.storage += p.StorageRequest
+		t.gpus += gpus(p.ExtendedRequests)
+		t.ephemeral += p.EphemeralStorageRequest
+	}
+
+	vm.Labels = sortedKeys(keys)
+	vm.Annotations = sortedKeys(annotations)
+	vm.Groups = make([]group, 0, len(groups))
+	for name, t := range groups {
+		vm.Groups = append(vm.Groups, group{
+			Name:             name,
+			Color:            groupColor(name),
+			Pods:             t.pods,
+			CPUUsage:         cpuMilliToHumanReadable(t.cpu),
+			MemoryUsage:      memoryBytesToHumanReadable(t.memory),
+			StorageRequest:   memoryBytesToHumanReadable(t.storage),
+			GPUs:             t.gpus,
+			EphemeralRequest: memoryBytesToHumanReadable(t.ephemeral),
+		})
+	}
+	sort.Slice(vm.Groups, func(i, j int) bool {
+		// Pods without the label go last.
+		if (vm.Groups[i].Name == noLabel) != (vm.Groups[j].Name == noLabel) {
+			return vm.Groups[j].Name == noLabel
+		}
+		return vm.Groups[i].Name < vm.Groups[j].Name
+	})
+	return vm, nil
+}
+
+// podGroup is the value of the pod's label, or annotation with
+// annotationPrefix, under key.
+func podGroup(p kubeclient.Pod, key string) string {
+	if annotation, ok := strings.CutPrefix(key, annotationPrefix); ok {
+		return labelGroup(p.Annotations, annotation)
+	}
+	return labelGroup(p.Labels, key)
+}
+
+func labelGroup(labels map[string]string, key string) string {
+	if value, ok := labels[key]; ok && value != "" {
+		return value
+	}
+	return noLabel
+}
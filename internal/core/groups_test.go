@@ -0,0 +1,108 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Groups(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"shop", "payments", "empty"}, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			return kubeclient.Node{Name: name, AvailableCPU: 4000, AllocatableMemory: 8 << 30}, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "web", Namespace: "shop", Node: "node-1", CPUUsage: 200, MemoryUsage: 256 << 20,
+					Labels: map[string]string{"team": "storefront", "pod-template-hash": "abc"}},
+				{Name: "cart", Namespace: "shop", Node: "node-1", CPUUsage: 100, MemoryUsage: 256 << 20,
+					Labels: map[string]string{"team": "storefront"}, Annotations: map[string]string{"owner": "alice"}},
+				{Name: "ledger", Namespace: "payments", Node: "node-1", CPUUsage: 300, MemoryUsage: 1 << 30,
+					Labels: map[string]string{"team": "billing", "app.kubernetes.io/part-of": "checkout"}},
+				{Name: "debug", Namespace: "payments", Node: "node-1"},
+			}, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given no label, then group by namespace including empty ones", func(t *testing.T) {
+		filter, err := service.GetFilter(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Empty(t, filter.Label)
+		assert.Equal(t, []string{"app.kubernetes.io/part-of", "team"}, filter.Labels)
+		assert.Equal(t, 3, len(filter.Groups))
+
+		assert.Equal(t, "empty", filter.Groups[0].Name)
+		assert.Equal(t, 0, filter.Groups[0].Pods)
+		assert.Equal(t, "payments", filter.Groups[1].Name)
+		assert.Equal(t, 2, filter.Groups[1].Pods)
+		assert.Equal(t, "shop", filter.Groups[2].Name)
+		assert.Equal(t, 2, filter.Groups[2].Pods)
+		assert.Equal(t, "300m", filter.Groups[2].CPUUsage)
+		assert.Equal(t, "512Mi", filter.Groups[2].MemoryUsage)
+	})
+
+	t.Run("given a label, then group by its values with unlabeled pods last", func(t *testing.T) {
+		filter, err := service.GetFilter(context.Background(), "team")
+		assert.Nil(t, err)
+		assert.Equal(t, "team", filter.Label)
+		assert.Equal(t, 3, len(filter.Groups))
+		assert.Equal(t, "billing", filter.Groups[0].Name)
+		assert.Equal(t, "storefront", filter.Groups[1].Name)
+		assert.Equal(t, 2, filter.Groups[1].Pods)
+		assert.Equal(t, "(none)", filter.Groups[2].Name)
+		assert.Equal(t, 1, filter.Groups[2].Pods)
+	})
+
+	t.Run("given a label, then color pods by its value", func(t *testing.T) {
+		pods, err := service.QueryPods(context.Background(), "node-1", core.PodQuery{Label: "team"})
+		assert.Nil(t, err)
+		assert.Equal(t, "storefront", pods[0].Group)
+		assert.Equal(t, pods[0].Color, pods[1].Color)
+		assert.Equal(t, "billing", pods[2].Group)
+		assert.NotEqual(t, pods[0].Color, pods[2].Color)
+		assert.Equal(t, "(none)", pods[3].Group)
+
+		byNamespace, err := service.GetPods(context.Background(), "node-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "shop", byNamespace[0].Group)
+		assert.Empty(t, byNamespace[0].Label)
+	})
+
+	t.Run("given an annotation, then group and color pods by its value", func(t *testing.T) {
+		filter, err := service.GetFilter(context.Background(), "annotation:owner")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"owner"}, filter.Annotations)
+		assert.Equal(t, 2, len(filter.Groups))
+		assert.Equal(t, "alice", filter.Groups[0].Name)
+		assert.Equal(t, 1, filter.Groups[0].Pods)
+		assert.Equal(t, "(none)", filter.Groups[1].Name)
+
+		pods, err := service.QueryPods(context.Background(), "node-1", core.PodQuery{Label: "annotation:owner"})
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", pods[1].Group)
+		assert.Equal(t, "(none)", pods[0].Group)
+	})
+
+	t.Run("given the sidebar with a label, then list its values and keep it selected", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		rec := httptest.NewRecorder()
+		handler.GetNamespaces(rec, httptest.NewRequest(http.MethodGet, "/namespaces?label=team", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `<option value="team" selected>team</option>`)
+		assert.Contains(t, rec.Body.String(), "storefront")
+		assert.NotContains(t, rec.Body.String(), "pod-template-hash")
+		assert.Contains(t, rec.Body.String(), `<option value="annotation:owner" >owner</option>`)
+	})
+}
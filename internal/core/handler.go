@@ -4,13 +4,32 @@ import (
 	"context"
 	"html/template"
 	"net/http"
+	"time"
 )
 
 type service interface {
 	// GetViewModel(ctx context.Context, namespace string, mode string) *viewModel
 	GetNamespaces(ctx context.Context) ([]namespace, error)
 	GetNodes(ctx context.Context) ([]node, error)
+	GetNodeGroups(ctx context.Context, label string, query NodeQuery) ([]nodeGroup, []string, error)
+	GetPendingPods(ctx context.Context) ([]pendingPod, error)
 	GetPods(ctx context.Context, node string) ([]pod, error)
+	QueryPods(ctx context.Context, node string, query PodQuery) ([]pod, error)
+	QueryNodes(ctx context.Context, query NodeQuery) ([]nodeSummary, error)
+	GetFilter(ctx context.Context, label string) (filterViewModel, error)
+	GetUsageRows(ctx context.Context, namespace string, node string) ([][]interface{}, error)
+	GetCosts(ctx context.Context, groupBy string, label string) (costViewModel, error)
+	GetRecommendations(ctx context.Context, namespace string, window time.Duration) ([]recommendation, error)
+	GetRisks(ctx context.Context) ([]nodeRisk, error)
+	SimulateDrain(ctx context.Context, node string) (drainViewModel, error)
+	GetConsolidation(ctx context.Context) (consolidationViewModel, error)
+	GetEvents(ctx context.Context, filter EventFilter) (eventViewModel, error)
+	GetTroubledPods(ctx context.Context) ([]troubledPod, error)
+	GetAlerts(ctx context.Context) ([]alertRule, error)
+	GetTreemap(ctx context.Context, query TreemapQuery) (treemapViewModel, error)
+	GetHeatmap(ctx context.Context, query HeatmapQuery) (heatmapViewModel, error)
+	GetStorage(ctx context.Context) (storageViewModel, error)
+	GetDevices(ctx context.Context, resource string) (devicesViewModel, error)
 }
 
 type Handler struct {
@@ -30,33 +49,60 @@ func NewHandler(tmpl *template.Template, service service) *Handler {
 }
 
 func (h *Handler) GetIndex(w http.ResponseWriter, r *http.Request) {
-	err := h.tmpl.ExecuteTemplate(w, "index.html", nil)
+	err := h.tmpl.ExecuteTemplate(w, "index.html", pageViewModel{Content: "/nodes"})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// render executes the fragment for htmx requests. Other requests, like
+// following a link to the page, get the layout loading the fragment into the
+// content area.
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, name string, data interface{}) {
+	var err error
+	if r.Header.Get("HX-Request") == "" {
+		err = h.tmpl.ExecuteTemplate(w, "index.html", pageViewModel{Content: r.URL.RequestURI()})
+	} else {
+		err = h.tmpl.ExecuteTemplate(w, name, data)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func (h *Handler) GetNamespaces(w http.ResponseWriter, r *http.Request) {
-	namespaces, err := h.service.GetNamespaces(r.Context())
+	filter, err := h.service.GetFilter(r.Context(), r.URL.Query().Get("label"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	err = h.tmpl.ExecuteTemplate(w, "filter.html", namespaces)
+	err = h.tmpl.ExecuteTemplate(w, "filter.html", filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func (h *Handler) GetNodes(w http.ResponseWriter, r *http.Request) {
-	nodes, err := h.service.GetNodes(r.Context())
+	groupBy := r.URL.Query().Get("group")
+	query := nodeQuery(r)
+	groups, labels, err := h.service.GetNodeGroups(r.Context(), groupBy, query)
 	vm := nodeViewModel{
-		Nodes: nodes,
-		Title: "Nodes",
+		Title:       "Nodes",
+		Groups:      groups,
+		GroupBy:     groupBy,
+		GroupLabels: labels,
+		Sort:        query.Sort,
+		Search:      query.Search,
+	}
+	for _, g := range groups {
+		vm.Nodes = append(vm.Nodes, g.Nodes...)
 	}
 	if err != nil {
 		vm.Error = err.Error()
 	}
+	if vm.Pending, err = h.service.GetPendingPods(r.Context()); err != nil && vm.Error == "" {
+		vm.Error = err.Error()
+	}
 	err = h.tmpl.ExecuteTemplate(w, "core.html", vm)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -65,7 +111,10 @@ func (h *Handler) GetNodes(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) GetPods(w http.ResponseWriter, r *http.Request) {
 	node := r.URL.Query().Get("node")
-	pods, err := h.service.GetPods(r.Context(), node)
+	pods, err := h.service.QueryPods(r.Context(), node, PodQuery{
+		Label:  r.URL.Query().Get("label"),
+		Search: r.URL.Query().Get("q"),
+	})
 	var errorMsg string
 	if err != nil {
 		errorMsg = err.Error()
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	defaultHeatmapWindow = 6 * time.Hour
+	heatmapBuckets       = 48
+	// heatmapLabelEvery is how many columns apart the time axis is labeled.
+	heatmapLabelEvery = 8
+	heatmapEmptyColor = "#E2E8F0"
+)
+
+// HeatmapQuery selects the utilization, CPU or memory, and how far back the
+// heatmap goes.
+type HeatmapQuery struct {
+	Mode   string
+	Window time.Duration
+}
+
+type (
+	heatmapViewModel struct {
+		Mode   string        `json:"mode"`
+		From   time.Time     `json:"from"`
+		To     time.Time     `json:"to"`
+		Bucket time.Duration `json:"-"`
+		// BucketSeconds is the width of a column.
+		BucketSeconds float64      `json:"bucketSeconds"`
+		Rows          []heatmapRow `json:"nodes"`
+		Labels        []string     `json:"-"`
+		Windows       []viewLink   `json:"-"`
+		Modes         []viewLink   `json:"-"`
+		Error         string       `json:"-"`
+	}
+	heatmapRow struct {
+		Node string `json:"name"`
+		// Peak is the highest utilization of the node in the window.
+		Peak  float64       `json:"peak"`
+		Cells []heatmapCell `json:"cells"`
+	}
+	// heatmapCell is the average and peak utilization of a node in percent
+	// over the samples of a bucket.
+	heatmapCell struct {
+		Start       time.Time `json:"start"`
+		Utilization float64   `json:"utilization"`
+		Peak        float64   `json:"peak"`
+		Samples     int       `json:"samples"`
+		Color       string    `json:"-"`
+		Title       string    `json:"-"`
+	}
+)
+
+// GetHeatmap buckets the usage history of every visible node into columns of
+// the window, with the utilization of the node by its pods in each.
+func (s *Service) GetHeatmap(ctx context.Context, query HeatmapQuery) (heatmapViewModel, error) {
+	if query.Mode != Memory {
+		query.Mode = CPU
+	}
+	if query.Window <= 0 {
+		query.Window = defaultHeatmapWindow
+	}
+	now := s.now()
+	vm := heatmapViewModel{
+		Mode:   query.Mode,
+		From:   now.Add(-query.Window),
+		To:     now,
+		Bucket: query.Window / heatmapBuckets,
+	}
+	vm.BucketSeconds = vm.Bucket.Seconds()
+	for _, window := range []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour} {
+		vm.Windows = append(vm.Windows, viewLink{
+			Name:   fmt.Sprintf("%dh", int(window.Hours())),
+			Href:   fmt.Sprintf("/heatmap?mode=%s&window=%dh", query.Mode, int(window.Hours())),
+			Active: window == query.Window,
+		})
+	}
+	vm.Modes = []viewLink{
+		{Name: "CPU", Href: fmt.Sprintf("/heatmap?mode=%s&window=%s", CPU, query.Window), Active: query.Mode == CPU},
+		{Name: "Memory", Href: fmt.Sprintf("/heatmap?mode=%s&window=%s", Memory, query.Window), Active: query.Mode == Memory},
+	}
+	for i := 0; i < heatmapBuckets; i++ {
+		label := ""
+		if i%heatmapLabelEvery == 0 {
+			label = vm.From.Add(time.Duration(i) * vm.Bucket).UTC().Format("15:04")
+		}
+		vm.Labels = append(vm.Labels, label)
+	}
+
+	history, err := s.kube.GetHistory(ctx, vm.From)
+	if err != nil {
+		return vm, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return vm, err
+	}
+	visibleNodes, err := s.visibleNodes(ctx, access)
+	if err != nil {
+		return vm, err
+	}
+
+	type bucket struct {
+		sum, peak float64
+		samples   int
+	}
+	buckets := make(map[string][]bucket)
+	for _, sample := range history {
+		if sample.Time.Before(vm.From) {
+			continue
+		}
+		i := min(int(sample.Time.Sub(vm.From)/vm.Bucket), heatmapBuckets-1)
+		usage := make(map[string]int64)
+		for _, p := range sample.Pods {
+			if query.Mode == Memory {
+				usage[p.Node] += p.MemoryUsage
+			} else {
+				usage[p.Node] += p.CPUUsage
+			}
+		}
+		for _, n := range sample.Nodes {
+			if visibleNodes != nil && !visibleNodes[n.Name] {
+				continue
+			}
+			allocatable := n.AvailableCPU
+			if query.Mode == Memory {
+				allocatable = n.AllocatableMemory
+			}
+			if _, ok := buckets[n.Name]; !ok {
+				buckets[n.Name] = make([]bucket, heatmapBuckets)
+			}
+			utilization := percent(usage[n.Name], allocatable)
+			b := &buckets[n.Name][i]
+			b.sum += utilization
+			b.peak = max(b.peak, utilization)
+			b.samples++
+		}
+	}
+
+	names := make([]string, 0, len(buckets))
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		row := heatmapRow{Node: name}
+		for i, b := range buckets[name] {
+			start := vm.From.Add(time.Duration(i) * vm.Bucket)
+			cell := heatmapCell{Start: start, Samples: b.samples, Color: heatmapEmptyColor}
+			span := fmt.Sprintf("%s–%s", start.UTC().Format("15:04"), start.Add(vm.Bucket).UTC().Format("15:04"))
+			cell.Title = fmt.Sprintf("%s | %s | no samples", name, span)
+			if b.samples > 0 {
+				cell.Utilization = b.sum / float64(b.samples)
+				cell.Peak = b.peak
+				cell.Color = heatColor(cell.Utilization)
+				cell.Title = fmt.Sprintf("%s | %s | average %.0f%% | peak %.0f%%", name, span, cell.Utilization, cell.Peak)
+				row.Peak = max(row.Peak, b.peak)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		vm.Rows = append(vm.Rows, row)
+	}
+	return vm, nil
+}
+
+// heatColors are the colors of no, half and full utilization, green, yellow
+// and red.
+var heatColors = [3][3]float64{{0x16, 0xA3, 0x4A}, {0xEA, 0xB3, 0x08}, {0xDC, 0x26, 0x26}}
+
+// heatColor blends the heat colors by utilization, red from 100%. The
+// template escapes CSS functions like hsl() in style attributes, so the color
+// is hex.
+func heatColor(utilization float64) string {
+	t := min(max(utilization, 0), 100) / 50
+	from, to := heatColors[0], heatColors[1]
+	if t > 1 {
+		from, to, t = heatColors[1], heatColors[2], t-1
+	}
+	var rgb [3]int
+	for i := range rgb {
+		rgb[i] = int(math.Round(from[i] + (to[i]-from[i])*t))
+	}
+	return fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2])
+}
+
+func heatmapQuery(r *http.Request) (HeatmapQuery, error) {
+	window, err := rightsizingWindow(r)
+	return HeatmapQuery{Mode: r.URL.Query().Get("mode"), Window: window}, err
+}
+
+func (h *Handler) GetHeatmap(w http.ResponseWriter, r *http.Request) {
+	query, err := heatmapQuery(r)
+	var vm heatmapViewModel
+	if err == nil {
+		vm, err = h.service.GetHeatmap(r.Context(), query)
+	}
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "heatmap.html", vm)
+}
+
+// GetHeatmapJSON serves the heatmap cells of every node, for ?mode= and
+// ?window=.
+func (h *Handler) GetHeatmapJSON(w http.ResponseWriter, r *http.Request) {
+	query, err := heatmapQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	vm, err := h.service.GetHeatmap(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vm)
+}
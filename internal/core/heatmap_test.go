@@ -0,0 +1,107 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Heatmap(t *testing.T) {
+	now := time.Now()
+	nodes := []kubeclient.NodeSample{
+		{Name: "node-1", AvailableCPU: 1000, AllocatableMemory: 4 << 30},
+		{Name: "node-2", AvailableCPU: 2000, AllocatableMemory: 4 << 30},
+	}
+	sample := func(ago time.Duration, cpu1, cpu2 int64) kubeclient.Sample {
+		return kubeclient.Sample{
+			Time:  now.Add(-ago),
+			Nodes: nodes,
+			Pods: []kubeclient.PodSample{
+				{Name: "web", Namespace: "shop", Node: "node-1", CPUUsage: cpu1, MemoryUsage: 1 << 30},
+				{Name: "ledger", Namespace: "payments", Node: "node-2", CPUUsage: cpu2, MemoryUsage: 3 << 30},
+			},
+		}
+	}
+	kube := &core.KubeMock{
+		GetHistoryFunc: func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+			return []kubeclient.Sample{
+				sample(7*time.Hour, 1000, 1000),
+				sample(5*time.Hour+59*time.Minute, 200, 500),
+				sample(5*time.Hour+58*time.Minute, 400, 500),
+				sample(time.Minute, 950, 100),
+			}, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given the usage history, then bucket node utilization over the window", func(t *testing.T) {
+		vm, err := service.GetHeatmap(context.Background(), core.HeatmapQuery{})
+		assert.Nil(t, err)
+		assert.Equal(t, "cpu", vm.Mode)
+		assert.Equal(t, 2, len(vm.Rows))
+
+		node1 := vm.Rows[0]
+		assert.Equal(t, "node-1", node1.Node)
+		assert.Equal(t, 48, len(node1.Cells))
+		assert.Equal(t, 2, node1.Cells[0].Samples)
+		assert.InDelta(t, 30.0, node1.Cells[0].Utilization, 0.01)
+		assert.InDelta(t, 40.0, node1.Cells[0].Peak, 0.01)
+		assert.Equal(t, 0, node1.Cells[1].Samples)
+		assert.InDelta(t, 95.0, node1.Cells[47].Utilization, 0.01)
+		assert.InDelta(t, 95.0, node1.Peak, 0.01)
+
+		assert.Equal(t, "node-2", vm.Rows[1].Node)
+		assert.InDelta(t, 25.0, vm.Rows[1].Cells[0].Utilization, 0.01)
+	})
+
+	t.Run("given memory and a window, then use them", func(t *testing.T) {
+		vm, err := service.GetHeatmap(context.Background(), core.HeatmapQuery{Mode: "memory", Window: time.Hour})
+		assert.Nil(t, err)
+		assert.Equal(t, 75*time.Second, vm.Bucket)
+		assert.Equal(t, 1, vm.Rows[1].Cells[47].Samples)
+		assert.InDelta(t, 75.0, vm.Rows[1].Cells[47].Utilization, 0.01)
+	})
+
+	t.Run("given the heatmap page, then render colored cells", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		req := httptest.NewRequest(http.MethodGet, "/heatmap", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetHeatmap(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "background-color: #DD3423")
+		assert.Contains(t, rec.Body.String(), "average 95% | peak 95%")
+		assert.NotContains(t, rec.Body.String(), "ZgotmplZ")
+	})
+
+	t.Run("given the heatmap API, then serve the cells as JSON", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		rec := httptest.NewRecorder()
+		handler.GetHeatmapJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/heatmap.json?window=6h", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			BucketSeconds float64 `json:"bucketSeconds"`
+			Nodes         []struct {
+				Name  string `json:"name"`
+				Cells []struct {
+					Utilization float64 `json:"utilization"`
+				} `json:"cells"`
+			} `json:"nodes"`
+		}
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, 450.0, body.BucketSeconds)
+		assert.Equal(t, "node-1", body.Nodes[0].Name)
+		assert.InDelta(t, 95.0, body.Nodes[0].Cells[47].Utilization, 0.01)
+
+		rec = httptest.NewRecorder()
+		handler.GetHeatmapJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/heatmap.json?window=soon", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"github.com/jawahars16/hawk8s/internal/kubeclient"
 	"sync"
+	"time"
 )
 
 // Ensure, that KubeMock does implement Kube.
@@ -19,6 +20,21 @@ var _ Kube = &KubeMock{}
 //
 //		// make and configure a mocked Kube
 //		mockedKube := &KubeMock{
+//			GetClaimsFunc: func(ctx context.Context) ([]kubeclient.Claim, error) {
+//				panic("mock out the GetClaims method")
+//			},
+//			GetDisruptionBudgetsFunc: func(ctx context.Context) ([]kubeclient.DisruptionBudget, error) {
+//				panic("mock out the GetDisruptionBudgets method")
+//			},
+//			GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+//				panic("mock out the GetEvents method")
+//			},
+//			GetHistoryFunc: func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+//				panic("mock out the GetHistory method")
+//			},
+//			GetLargestNodeStorageFunc: func(ctx context.Context) (int64, error) {
+//				panic("mock out the GetLargestNodeStorage method")
+//			},
 //			GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
 //				panic("mock out the GetNamespaces method")
 //			},
@@ -31,6 +47,12 @@ var _ Kube = &KubeMock{}
 //			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
 //				panic("mock out the GetPods method")
 //			},
+//			GetStorageClassesFunc: func(ctx context.Context) ([]kubeclient.StorageClass, error) {
+//				panic("mock out the GetStorageClasses method")
+//			},
+//			GetVolumesFunc: func(ctx context.Context) ([]kubeclient.Volume, error) {
+//				panic("mock out the GetVolumes method")
+//			},
 //		}
 //
 //		// use mockedKube in code that requires Kube
@@ -38,6 +60,21 @@ var _ Kube = &KubeMock{}
 //
 //	}
 type KubeMock struct {
+	// GetClaimsFunc mocks the GetClaims method.
+	GetClaimsFunc func(ctx context.Context) ([]kubeclient.Claim, error)
+
+	// GetDisruptionBudgetsFunc mocks the GetDisruptionBudgets method.
+	GetDisruptionBudgetsFunc func(ctx context.Context) ([]kubeclient.DisruptionBudget, error)
+
+	// GetEventsFunc mocks the GetEvents method.
+	GetEventsFunc func(ctx context.Context) ([]kubeclient.Event, error)
+
+	// GetHistoryFunc mocks the GetHistory method.
+	GetHistoryFunc func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error)
+
+	// GetLargestNodeStorageFunc mocks the GetLargestNodeStorage method.
+	GetLargestNodeStorageFunc func(ctx context.Context) (int64, error)
+
 	// GetNamespacesFunc mocks the GetNamespaces method.
 	GetNamespacesFunc func(ctx context.Context) ([]string, error)
 
@@ -50,8 +87,41 @@ type KubeMock struct {
 	// GetPodsFunc mocks the GetPods method.
 	GetPodsFunc func(ctx context.Context, node string) ([]kubeclient.Pod, error)
 
+	// GetStorageClassesFunc mocks the GetStorageClasses method.
+	GetStorageClassesFunc func(ctx context.Context) ([]kubeclient.StorageClass, error)
+
+	// GetVolumesFunc mocks the GetVolumes method.
+	GetVolumesFunc func(ctx context.Context) ([]kubeclient.Volume, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// GetClaims holds details about calls to the GetClaims method.
+		GetClaims []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetDisruptionBudgets holds details about calls to the GetDisruptionBudgets method.
+		GetDisruptionBudgets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetEvents holds details about calls to the GetEvents method.
+		GetEvents []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetHistory holds details about calls to the GetHistory method.
+		GetHistory []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Since is the since argument value.
+			Since time.Time
+		}
+		// GetLargestNodeStorage holds details about calls to the GetLargestNodeStorage method.
+		GetLargestNodeStorage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
 		// GetNamespaces holds details about calls to the GetNamespaces method.
 		GetNamespaces []struct {
 			// Ctx is the ctx argument value.
@@ -76,11 +146,192 @@ type KubeMock struct {
 			// Node is the node argument value.
 			Node string
 		}
+		// GetStorageClasses holds details about calls to the GetStorageClasses method.
+		GetStorageClasses []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetVolumes holds details about calls to the GetVolumes method.
+		GetVolumes []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockGetClaims             sync.RWMutex
+	lockGetDisruptionBudgets  sync.RWMutex
+	lockGetEvents             sync.RWMutex
+	lockGetHistory            sync.RWMutex
+	lockGetLargestNodeStorage sync.RWMutex
+	lockGetNamespaces         sync.RWMutex
+	lockGetNode               sync.RWMutex
+	lockGetNodes              sync.RWMutex
+	lockGetPods               sync.RWMutex
+	lockGetStorageClasses     sync.RWMutex
+	lockGetVolumes            sync.RWMutex
+}
+
+// GetClaims calls GetClaimsFunc.
+func (mock *KubeMock) GetClaims(ctx context.Context) ([]kubeclient.Claim, error) {
+	if mock.GetClaimsFunc == nil {
+		panic("KubeMock.GetClaimsFunc: method is nil but Kube.GetClaims was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetClaims.Lock()
+	mock.calls.GetClaims = append(mock.calls.GetClaims, callInfo)
+	mock.lockGetClaims.Unlock()
+	return mock.GetClaimsFunc(ctx)
+}
+
+// GetClaimsCalls gets all the calls that were made to GetClaims.
+// Check the length with:
+//
+//	len(mockedKube.GetClaimsCalls())
+func (mock *KubeMock) GetClaimsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetClaims.RLock()
+	calls = mock.calls.GetClaims
+	mock.lockGetClaims.RUnlock()
+	return calls
+}
+
+// GetDisruptionBudgets calls GetDisruptionBudgetsFunc.
+func (mock *KubeMock) GetDisruptionBudgets(ctx context.Context) ([]kubeclient.DisruptionBudget, error) {
+	if mock.GetDisruptionBudgetsFunc == nil {
+		panic("KubeMock.GetDisruptionBudgetsFunc: method is nil but Kube.GetDisruptionBudgets was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetDisruptionBudgets.Lock()
+	mock.calls.GetDisruptionBudgets = append(mock.calls.GetDisruptionBudgets, callInfo)
+	mock.lockGetDisruptionBudgets.Unlock()
+	return mock.GetDisruptionBudgetsFunc(ctx)
+}
+
+// GetDisruptionBudgetsCalls gets all the calls that were made to GetDisruptionBudgets.
+// Check the length with:
+//
+//	len(mockedKube.GetDisruptionBudgetsCalls())
+func (mock *KubeMock) GetDisruptionBudgetsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetDisruptionBudgets.RLock()
+	calls = mock.calls.GetDisruptionBudgets
+	mock.lockGetDisruptionBudgets.RUnlock()
+	return calls
+}
+
+// GetEvents calls GetEventsFunc.
+func (mock *KubeMock) GetEvents(ctx context.Context) ([]kubeclient.Event, error) {
+	if mock.GetEventsFunc == nil {
+		panic("KubeMock.GetEventsFunc: method is nil but Kube.GetEvents was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
 	}
-	lockGetNamespaces sync.RWMutex
-	lockGetNode       sync.RWMutex
-	lockGetNodes      sync.RWMutex
-	lockGetPods       sync.RWMutex
+	mock.lockGetEvents.Lock()
+	mock.calls.GetEvents = append(mock.calls.GetEvents, callInfo)
+	mock.lockGetEvents.Unlock()
+	return mock.GetEventsFunc(ctx)
+}
+
+// GetEventsCalls gets all the calls that were made to GetEvents.
+// Check the length with:
+//
+//	len(mockedKube.GetEventsCalls())
+func (mock *KubeMock) GetEventsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetEvents.RLock()
+	calls = mock.calls.GetEvents
+	mock.lockGetEvents.RUnlock()
+	return calls
+}
+
+// GetHistory calls GetHistoryFunc.
+func (mock *KubeMock) GetHistory(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+	if mock.GetHistoryFunc == nil {
+		panic("KubeMock.GetHistoryFunc: method is nil but Kube.GetHistory was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Since time.Time
+	}{
+		Ctx:   ctx,
+		Since: since,
+	}
+	mock.lockGetHistory.Lock()
+	mock.calls.GetHistory = append(mock.calls.GetHistory, callInfo)
+	mock.lockGetHistory.Unlock()
+	return mock.GetHistoryFunc(ctx, since)
+}
+
+// GetHistoryCalls gets all the calls that were made to GetHistory.
+// Check the length with:
+//
+//	len(mockedKube.GetHistoryCalls())
+func (mock *KubeMock) GetHistoryCalls() []struct {
+	Ctx   context.Context
+	Since time.Time
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Since time.Time
+	}
+	mock.lockGetHistory.RLock()
+	calls = mock.calls.GetHistory
+	mock.lockGetHistory.RUnlock()
+	return calls
+}
+
+// GetLargestNodeStorage calls GetLargestNodeStorageFunc.
+func (mock *KubeMock) GetLargestNodeStorage(ctx context.Context) (int64, error) {
+	if mock.GetLargestNodeStorageFunc == nil {
+		panic("KubeMock.GetLargestNodeStorageFunc: method is nil but Kube.GetLargestNodeStorage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetLargestNodeStorage.Lock()
+	mock.calls.GetLargestNodeStorage = append(mock.calls.GetLargestNodeStorage, callInfo)
+	mock.lockGetLargestNodeStorage.Unlock()
+	return mock.GetLargestNodeStorageFunc(ctx)
+}
+
+// GetLargestNodeStorageCalls gets all the calls that were made to GetLargestNodeStorage.
+// Check the length with:
+//
+//	len(mockedKube.GetLargestNodeStorageCalls())
+func (mock *KubeMock) GetLargestNodeStorageCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetLargestNodeStorage.RLock()
+	calls = mock.calls.GetLargestNodeStorage
+	mock.lockGetLargestNodeStorage.RUnlock()
+	return calls
 }
 
 // GetNamespaces calls GetNamespacesFunc.
@@ -218,3 +469,67 @@ func (mock *KubeMock) GetPodsCalls() []struct {
 	mock.lockGetPods.RUnlock()
 	return calls
 }
+
+// GetStorageClasses calls GetStorageClassesFunc.
+func (mock *KubeMock) GetStorageClasses(ctx context.Context) ([]kubeclient.StorageClass, error) {
+	if mock.GetStorageClassesFunc == nil {
+		panic("KubeMock.GetStorageClassesFunc: method is nil but Kube.GetStorageClasses was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetStorageClasses.Lock()
+	mock.calls.GetStorageClasses = append(mock.calls.GetStorageClasses, callInfo)
+	mock.lockGetStorageClasses.Unlock()
+	return mock.GetStorageClassesFunc(ctx)
+}
+
+// GetStorageClassesCalls gets all the calls that were made to GetStorageClasses.
+// Check the length with:
+//
+//	len(mockedKube.GetStorageClassesCalls())
+func (mock *KubeMock) GetStorageClassesCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetStorageClasses.RLock()
+	calls = mock.calls.GetStorageClasses
+	mock.lockGetStorageClasses.RUnlock()
+	return calls
+}
+
+// GetVolumes calls GetVolumesFunc.
+func (mock *KubeMock) GetVolumes(ctx context.Context) ([]kubeclient.Volume, error) {
+	if mock.GetVolumesFunc == nil {
+		panic("KubeMock.GetVolumesFunc: method is nil but Kube.GetVolumes was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetVolumes.Lock()
+	mock.calls.GetVolumes = append(mock.calls.GetVolumes, callInfo)
+	mock.lockGetVolumes.Unlock()
+	return mock.GetVolumesFunc(ctx)
+}
+
+// GetVolumesCalls gets all the calls that were made to GetVolumes.
+// Check the length with:
+//
+//	len(mockedKube.GetVolumesCalls())
+func (mock *KubeMock) GetVolumesCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetVolumes.RLock()
+	calls = mock.calls.GetVolumes
+	mock.lockGetVolumes.RUnlock()
+	return calls
+}
@@ -5,23 +5,50 @@ import (
 )
 
 type (
+	pageViewModel struct {
+		// Content is the URL of the fragment shown in the content area.
+		Content string
+	}
 	nodeViewModel struct {
 		Nodes           []node
+		Pending         []pendingPod
 		ActiveNamespace string
 		ActiveMode      string
 		Title           string
 		Namespaces      []namespace
 		Modes           []mode
 		Error           string
+		// Groups are the nodes grouped by the GroupBy node label, one group
+		// without a name when GroupBy is empty.
+		Groups      []nodeGroup
+		GroupBy     string
+		GroupLabels []string
+		Sort        string
+		Search      string
 	}
 	podViewModel struct {
 		Pods  []pod
 		Error string
 	}
 	node struct {
-		Name string
-		Info string
-		Pods []pod
+		Name        string
+		Info        string
+		Pods        []pod
+		CpuMillis   int64
+		MemoryBytes int64
+		Risk        nodeRisk
+		Age         string
+		// Matches is the number of pods matching the search.
+		Matches int
+		// PodCount counts the pods holding a slot of the node's MaxPods,
+		// EphemeralRequest is their ephemeral storage requests against the
+		// node's allocatable EphemeralStorage.
+		PodCount                int
+		MaxPods                 int64
+		PodSlotsPercent         float64
+		EphemeralStorage        string
+		EphemeralRequest        string
+		EphemeralRequestPercent float64
 	}
 	pod struct {
 		Name        string
@@ -30,8 +57,39 @@ type (
 		Color       string
 		Namespace   string
 		Status      string
+		Ready       string
 		CpuUsage    string
 		MemoryUsage string
+		CpuShare    float32
+		MemoryShare float32
+		CpuMillis   int64
+		MemoryBytes int64
+		// Warnings are the recent warning events of the pod, newest first.
+		Warnings []string
+		Restarts int32
+		// Trouble is CrashLoopBackOff or OOMKilled when the pod is in that
+		// state.
+		Trouble string
+		// Group is the value of the Label key the pod is colored by, or its
+		// namespace when Label is empty.
+		Label string
+		Group string
+		// Dimmed is set on pods not matching the search.
+		Dimmed bool
+		// StorageSize is the share of the storage claimed by the pod of the
+		// most storage claimed on a node, so that nodes compare in storage
+		// mode.
+		StorageSize    string
+		StorageRequest string
+		StorageUsage   string
+		// GPUSize is the share of the node's GPUs the pod requests.
+		GPUSize string
+		GPUs    int64
+		// EphemeralSize is the share of the node's ephemeral storage the pod
+		// requests, SlotSize the share of its pod slots.
+		EphemeralSize    string
+		EphemeralRequest string
+		SlotSize         string
 	}
 	mode struct {
 		Name  string
@@ -46,8 +104,15 @@ type (
 func namespaceByName(name string) namespace {
 	return namespace{
 		Name:  name,
-		Color: namespaceColors[hash(name)%uint32(len(namespaceColors))],
+		Color: groupColor(name),
+	}
+}
+
+func groupColor(name string) string {
+	if name == noLabel {
+		return noLabelColor
 	}
+	return namespaceColors[hash(name)%uint32(len(namespaceColors))]
 }
 
 func hash(s string) uint32 {
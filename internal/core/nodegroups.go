@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+// hostnameLabel is unique to every node, so grouping by it is pointless.
+const hostnameLabel = "kubernetes.io/hostname"
+
+type nodeGroup struct {
+	// Name is the value of the label the nodes are grouped by, empty when they
+	// are not grouped.
+	Name  string
+	Nodes []node
+	// CPU and Memory are the allocatable resources of the nodes, the rest the
+	// requests and usage of their pods and those against allocatable in
+	// percent.
+	CPU                  string
+	Memory               string
+	CPURequest           string
+	MemoryRequest        string
+	CPUUsage             string
+	MemoryUsage          string
+	CPURequestPercent    float64
+	MemoryRequestPercent float64
+	CPUUsagePercent      float64
+	MemoryUsagePercent   float64
+	// PodCount and MaxPods are the pods holding a slot of the nodes and their
+	// slots, EphemeralRequest the pods' requests of the nodes' allocatable
+	// EphemeralStorage.
+	PodCount                int
+	MaxPods                 int64
+	PodSlotsPercent         float64
+	EphemeralStorage        string
+	EphemeralRequest        string
+	EphemeralRequestPercent float64
+}
+
+// GetNodeGroups groups the nodes by the value of a node label, like the
+// topology zone or the node pool, with the totals of every group. Without a
+// label all nodes are in one group. Nodes are sorted, and their pods
+// searched, by the query.
+func (s *Service) GetNodeGroups(ctx context.Context, label string, query NodeQuery) ([]nodeGroup, []string, error) {
+	unsorted, err := s.GetNodes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	summaries, err := s.QueryNodes(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodeByName := make(map[string]node, len(unsorted))
+	for _, n := range unsorted {
+		nodeByName[n.Name] = n
+	}
+	nodes := make([]node, 0, len(summaries))
+	for _, summary := range summaries {
+		n, ok := nodeByName[summary.Name]
+		if !ok {
+			continue
+		}
+		n.Age = summary.Age
+		n.Matches = len(summary.Matches)
+		nodes = append(nodes, n)
+	}
+	kubeNodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]kubeclient.Node, len(kubeNodes))
+	for _, n := range kubeNodes {
+		byName[n.Name] = n
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, nil, err
+	}
+	// Finished pods hold no resources.
+	podsByNode := scheduler.Running(pods)
+
+	type totals struct {
+		cpu, memory                 int64
+		cpuRequest, memoryRequest   int64
+		cpuUsage, memoryUsage       int64
+		ephemeral, ephemeralRequest int64
+	}
+	keys := make(map[string]bool)
+	groups := make(map[string]*nodeGroup)
+	sums := make(map[string]*totals)
+	var names []string
+	for _, n := range nodes {
+		labels := byName[n.Name].Labels
+		for k := range labels {
+			if k != hostnameLabel {
+				keys[k] = true
+			}
+		}
+		name := ""
+		if label != "" {
+			name = labelGroup(labels, label)
+		}
+		g, ok := groups[name]
+		if !ok {
+			g = &nodeGroup{Name: name}
+			groups[name] = g
+			sums[name] = &totals{}
+			names = append(names, name)
+		}
+		g.Nodes = append(g.Nodes, n)
+		t := sums[name]
+		t.cpu += n.CpuMillis
+		t.memory += n.MemoryBytes
+		t.ephemeral += byName[n.Name].AllocatableEphemeralStorage
+		g.PodCount += n.PodCount
+		g.MaxPods += n.MaxPods
+		for _, p := range podsByNode[n.Name] {
+			t.ephemeralRequest += p.EphemeralStorageRequest
+			t.cpuRequest += p.CPURequest
+			t.memoryRequest += p.MemoryRequest
+			t.cpuUsage += p.CPUUsage
+			t.memoryUsage += p.MemoryUsage
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		// Nodes without the label go last.
+		if (names[i] == noLabel) != (names[j] == noLabel) {
+			return names[j] == noLabel
+		}
+		return names[i] < names[j]
+	})
+	result := make([]nodeGroup, 0, len(names))
+	for _, name := range names {
+		g, t := groups[name], sums[name]
+		g.CPU = cpuMilliToHumanReadable(t.cpu)
+		g.Memory = memoryBytesToHumanReadable(t.memory)
+		g.CPURequest = cpuMilliToHumanReadable(t.cpuRequest)
+		g.MemoryRequest = memoryBytesToHumanReadable(t.memoryRequest)
+		g.CPUUsage = cpuMilliToHumanReadable(t.cpuUsage)
+		g.MemoryUsage = memoryBytesToHumanReadable(t.memoryUsage)
+		g.CPURequestPercent = percent(t.cpuRequest, t.cpu)
+		g.MemoryRequestPercent = percent(t.memoryRequest, t.memory)
+		g.CPUUsagePercent = percent(t.cpuUsage, t.cpu)
+		g.MemoryUsagePercent = percent(t.memoryUsage, t.memory)
+		g.PodSlotsPercent = percent(int64(g.PodCount), g.MaxPods)
+		g.EphemeralStorage = memoryBytesToHumanReadable(t.ephemeral)
+		g.EphemeralRequest = memoryBytesToHumanReadable(t.ephemeralRequest)
+		g.EphemeralRequestPercent = percent(t.ephemeralRequest, t.ephemeral)
+		result = append(result, *g)
+	}
+	return result, sortedKeys(keys), nil
+}
@@ -0,0 +1,78 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NodeGroups(t *testing.T) {
+	zone := "topology.kubernetes.io/zone"
+	kube := &core.KubeMock{
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{
+				{Name: "node-a1", AvailableCPU: 2000, AllocatableMemory: 4 << 30,
+					Labels: map[string]string{zone: "eu-west-1a", "kubernetes.io/hostname": "node-a1"}},
+				{Name: "node-a2", AvailableCPU: 2000, AllocatableMemory: 4 << 30,
+					Labels: map[string]string{zone: "eu-west-1a", "kubernetes.io/hostname": "node-a2"}},
+				{Name: "node-b1", AvailableCPU: 4000, AllocatableMemory: 8 << 30,
+					Labels: map[string]string{zone: "eu-west-1b", "kubernetes.io/hostname": "node-b1"}},
+				{Name: "virtual", AvailableCPU: 1000, AllocatableMemory: 1 << 30},
+			}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "web", Namespace: "shop", Node: "node-a1", CPURequest: 1000, CPUUsage: 500, MemoryRequest: 2 << 30, MemoryUsage: 1 << 30},
+				{Name: "cart", Namespace: "shop", Node: "node-a2", CPURequest: 2000, CPUUsage: 1500, MemoryRequest: 2 << 30, MemoryUsage: 3 << 30},
+				{Name: "ledger", Namespace: "payments", Node: "node-b1", CPURequest: 400, CPUUsage: 200},
+				{Name: "migrate", Namespace: "shop", Node: "node-a1", Phase: "Succeeded", CPURequest: 1000, MemoryRequest: 1 << 30},
+			}, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given no label, then put all nodes in one group", func(t *testing.T) {
+		groups, labels, err := service.GetNodeGroups(context.Background(), "", core.NodeQuery{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{zone}, labels)
+		assert.Equal(t, 1, len(groups))
+		assert.Empty(t, groups[0].Name)
+		assert.Equal(t, 4, len(groups[0].Nodes))
+	})
+
+	t.Run("given a zone label, then group nodes by zone with totals", func(t *testing.T) {
+		groups, _, err := service.GetNodeGroups(context.Background(), zone, core.NodeQuery{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(groups))
+
+		assert.Equal(t, "eu-west-1a", groups[0].Name)
+		assert.Equal(t, 2, len(groups[0].Nodes))
+		assert.Equal(t, "4", groups[0].CPU)
+		assert.Equal(t, "3", groups[0].CPURequest)
+		assert.Equal(t, 75.0, groups[0].CPURequestPercent)
+		assert.Equal(t, 50.0, groups[0].CPUUsagePercent)
+		assert.Equal(t, "4Gi", groups[0].MemoryUsage)
+		assert.Equal(t, 50.0, groups[0].MemoryUsagePercent)
+
+		assert.Equal(t, "eu-west-1b", groups[1].Name)
+		assert.Equal(t, 10.0, groups[1].CPURequestPercent)
+
+		assert.Equal(t, "(none)", groups[2].Name)
+		assert.Equal(t, "virtual", groups[2].Nodes[0].Name)
+	})
+
+	t.Run("given the nodes page grouped by zone, then render the groups", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		rec := httptest.NewRecorder()
+		handler.GetNodes(rec, httptest.NewRequest(http.MethodGet, "/nodes?group="+zone, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "<b>eu-west-1b</b>")
+		assert.Contains(t, rec.Body.String(), "requested 3 (75%)")
+		assert.Contains(t, rec.Body.String(), `<option value="`+zone+`" selected>`)
+	})
+}
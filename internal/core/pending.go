@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+type pendingPod struct {
+	Name      string
+	Namespace string
+	Color     string
+	// Reason and Message come from the PodScheduled condition, Event from the
+	// latest FailedScheduling event.
+	Reason        string
+	Message       string
+	Event         string
+	CPURequest    string
+	MemoryRequest string
+	// FreeCPU and FreeMemory are the largest unrequested CPU and memory on any
+	// node, with the node they are on.
+	FreeCPU        string
+	FreeCPUNode    string
+	FreeMemory     string
+	FreeMemoryNode string
+	// FitsCPU and FitsMemory tell if the requests would fit in the largest
+	// free slot.
+	FitsCPU    bool
+	FitsMemory bool
+	// Node is where the scheduler would place the pod now, if anywhere.
+	Node string
+}
+
+// GetPendingPods lists the pods that are not bound to a node yet, with why
+// they are not and how their requests compare with the free capacity.
+func (s *Service) GetPendingPods(ctx context.Context) ([]pendingPod, error) {
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []kubeclient.Pod
+	for _, p := range pods {
+		if p.Node == "" && p.Phase == "Pending" && access.namespace(p.Namespace) {
+			pending = append(pending, p)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	podsByNode := scheduler.Running(pods)
+	states := make([]*scheduler.NodeState, 0, len(nodes))
+	var freeCPU, freeMemory *scheduler.NodeState
+	for _, n := range nodes {
+		state := scheduler.NewNodeState(n, podsByNode[n.Name])
+		states = append(states, state)
+		if n.Unschedulable {
+			continue
+		}
+		if freeCPU == nil || free(state.AvailableCPU, state.CPURequest) > free(freeCPU.AvailableCPU, freeCPU.CPURequest) {
+			freeCPU = state
+		}
+		if freeMemory == nil || free(state.AllocatableMemory, state.MemoryRequest) > free(freeMemory.AllocatableMemory, freeMemory.MemoryRequest) {
+			freeMemory = state
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].Namespace != pending[j].Namespace {
+			return pending[i].Namespace < pending[j].Namespace
+		}
+		return pending[i].Name < pending[j].Name
+	})
+
+	result := make([]pendingPod, 0, len(pending))
+	for _, p := range pending {
+		row := pendingPod{
+			Name:          p.Name,
+			Namespace:     p.Namespace,
+			Color:         namespaceByName(p.Namespace).Color,
+			Reason:        p.ScheduledReason,
+			Message:       p.ScheduledMessage,
+			Event:         p.SchedulingFailure,
+			CPURequest:    cpuMilliToHumanReadable(p.CPURequest),
+			MemoryRequest: memoryBytesToHumanReadable(p.MemoryRequest),
+		}
+		if freeCPU != nil {
+			cpu := free(freeCPU.AvailableCPU, freeCPU.CPURequest)
+			row.FreeCPU = cpuMilliToHumanReadable(cpu)
+			row.FreeCPUNode = freeCPU.Name
+			row.FitsCPU = p.CPURequest <= cpu
+		}
+		if freeMemory != nil {
+			memory := free(freeMemory.AllocatableMemory, freeMemory.MemoryRequest)
+			row.FreeMemory = memoryBytesToHumanReadable(memory)
+			row.FreeMemoryNode = freeMemory.Name
+			row.FitsMemory = p.MemoryRequest <= memory
+		}
+		if target, _ := scheduler.Schedule(p, states); target != nil {
+			row.Node = target.Name
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+func free(allocatable, requested int64) int64 {
+	return max(allocatable-requested, 0)
+}
@@ -0,0 +1,62 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PendingPods(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{
+				{Name: "node1", Status: "Ready", AvailableCPU: 2000, AllocatableMemory: 4 << 30},
+				{Name: "node2", Status: "Ready", AvailableCPU: 1000, AllocatableMemory: 8 << 30},
+			}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "web", Namespace: "shop", Node: "node1", Phase: "Running", CPURequest: 1500, MemoryRequest: 1 << 30},
+				{
+					Name: "big", Namespace: "shop", Phase: "Pending", CPURequest: 1500, MemoryRequest: 1 << 30,
+					ScheduledReason: "Unschedulable", SchedulingFailure: "0/2 nodes are available: 2 Insufficient cpu.",
+				},
+				{Name: "small", Namespace: "shop", Phase: "Pending", CPURequest: 100, MemoryRequest: 1 << 30},
+			}, nil
+		},
+	}
+
+	t.Run("given pending pods, then compare their requests with the largest free slot", func(t *testing.T) {
+		pending, err := core.NewService(kube).GetPendingPods(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(pending))
+
+		assert.Equal(t, "big", pending[0].Name)
+		assert.Equal(t, "Unschedulable", pending[0].Reason)
+		assert.Equal(t, "0/2 nodes are available: 2 Insufficient cpu.", pending[0].Event)
+		assert.Equal(t, "node2", pending[0].FreeCPUNode)
+		assert.Equal(t, "node2", pending[0].FreeMemoryNode)
+		assert.False(t, pending[0].FitsCPU)
+		assert.True(t, pending[0].FitsMemory)
+		assert.Empty(t, pending[0].Node)
+
+		assert.Equal(t, "small", pending[1].Name)
+		assert.True(t, pending[1].FitsCPU)
+		assert.Equal(t, "node2", pending[1].Node)
+	})
+
+	t.Run("given the nodes page, then render the unscheduled lane", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), core.NewService(kube))
+		rec := httptest.NewRecorder()
+		handler.GetNodes(rec, httptest.NewRequest(http.MethodGet, "/nodes", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Unscheduled")
+		assert.Contains(t, rec.Body.String(), "shop/big")
+		assert.Contains(t, rec.Body.String(), "Insufficient cpu")
+	})
+}
@@ -0,0 +1,377 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	overProvisioned  = "over"
+	underProvisioned = "under"
+	rightsized       = "ok"
+	// insufficientData marks containers without usage samples, or that never
+	// used anything, which get no recommendation.
+	insufficientData = "insufficient"
+)
+
+// A request further than this factor from the recommendation is flagged.
+const rightsizingTolerance = 1.3
+
+const (
+	minCPURequest    = 10      // millicores
+	minMemoryRequest = 1 << 20 // bytes
+)
+
+// RightsizingOptions tunes the recommendations: CPU requests follow a
+// percentile of the observed usage, memory requests and limits the peak usage
+// plus headroom.
+type RightsizingOptions struct {
+	Window        time.Duration
+	CPUPercentile float64
+	Headroom      float64
+}
+
+var DefaultRightsizingOptions = RightsizingOptions{
+	Window:        24 * time.Hour,
+	CPUPercentile: 95,
+	Headroom:      0.15,
+}
+
+type (
+	recommendation struct {
+		Namespace    string    `json:"namespace"`
+		Workload     string    `json:"workload"`
+		Container    string    `json:"container"`
+		Samples      int       `json:"samples"`
+		Current      resources `json:"current"`
+		Recommended  resources `json:"recommended"`
+		CPUStatus    string    `json:"cpuStatus"`
+		MemoryStatus string    `json:"memoryStatus"`
+	}
+	// resources of a container, where a limit of 0 means no limit.
+	resources struct {
+		CPURequestMillis   int64 `json:"cpuRequestMillis"`
+		CPULimitMillis     int64 `json:"cpuLimitMillis"`
+		MemoryRequestBytes int64 `json:"memoryRequestBytes"`
+		MemoryLimitBytes   int64 `json:"memoryLimitBytes"`
+	}
+	recommendationViewModel struct {
+		Recommendations []recommendation
+		Window          string
+		Error           string
+	}
+	containerUsage struct {
+		recommendation
+		cpu    []int64
+		memory []int64
+	}
+)
+
+func WithRightsizing(opts RightsizingOptions) Option {
+	return func(s *Service) {
+		s.rightsizing = opts
+	}
+}
+
+// GetRecommendations recommends requests and limits for every container of
+// the workloads in namespace from its usage over the window, as far as the
+// usage history goes back. Limits are only recommended for containers that
+// set them. Without history the current usage is the only sample.
+func (s *Service) GetRecommendations(ctx context.Context, namespace string, window time.Duration) ([]recommendation, error) {
+	if window <= 0 {
+		window = s.rightsizing.Window
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, err
+	}
+	history, err := s.kube.GetHistory(ctx, s.now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	selected := func(ns string) bool {
+		return access.namespace(ns) && (namespace == "" || namespace == "all" || namespace == ns)
+	}
+	usage := make(map[string]*containerUsage)
+	for _, p := range pods {
+		if !selected(p.Namespace) {
+			continue
+		}
+		for _, c := range p.Containers {
+			key := rightsizingKey(p.Namespace, podWorkload(p.Workload, p.Name), c.Name)
+			if _, ok := usage[key]; ok {
+				continue
+			}
+			usage[key] = &containerUsage{recommendation: recommendation{
+				Namespace: p.Namespace,
+				Workload:  podWorkload(p.Workload, p.Name),
+				Container: c.Name,
+				Current: resources{
+					CPURequestMillis:   c.CPURequest,
+					CPULimitMillis:     c.CPULimit,
+					MemoryRequestBytes: c.MemoryRequest,
+					MemoryLimitBytes:   c.MemoryLimit,
+				},
+			}}
+		}
+	}
+
+	if len(history) == 0 {
+		for _, p := range pods {
+			for _, c := range p.Containers {
+				if u, ok := usage[rightsizingKey(p.Namespace, podWorkload(p.Workload, p.Name), c.Name)]; ok {
+					u.cpu = append(u.cpu, c.CPUUsage)
+					u.memory = append(u.memory, c.MemoryUsage)
+				}
+			}
+		}
+	}
+	for _, sample := range history {
+		for _, p := range sample.Pods {
+			for _, c := range p.Containers {
+				if u, ok := usage[rightsizingKey(p.Namespace, podWorkload(p.Workload, p.Name), c.Name)]; ok {
+					u.cpu = append(u.cpu, c.CPUUsage)
+					u.memory = append(u.memory, c.MemoryUsage)
+				}
+			}
+		}
+	}
+
+	recommendations := make([]recommendation, 0, len(usage))
+	for _, u := range usage {
+		recommendations = append(recommendations, s.recommend(u))
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		return rightsizingKey(a.Namespace, a.Workload, a.Container) < rightsizingKey(b.Namespace, b.Workload, b.Container)
+	})
+	return recommendations, nil
+}
+
+func (s *Service) recommend(u *containerUsage) recommendation {
+	r := u.recommendation
+	r.Samples = len(u.cpu)
+
+	cpu := percentile(u.cpu, s.rightsizing.CPUPercentile)
+	peakCPU := percentile(u.cpu, 100)
+	if r.Samples == 0 || peakCPU == 0 && percentile(u.memory, 100) == 0 {
+		r.CPUStatus = insufficientData
+		r.MemoryStatus = insufficientData
+		return r
+	}
+	peakMemory := float64(percentile(u.memory, 100)) * (1 + s.rightsizing.Headroom)
+
+	r.Recommended.CPURequestMillis = max(roundUp(cpu, 5), minCPURequest)
+	if r.Current.CPULimitMillis > 0 {
+		limit := roundUp(int64(math.Ceil(float64(peakCPU)*(1+s.rightsizing.Headroom))), 5)
+		r.Recommended.CPULimitMillis = max(limit, r.Recommended.CPURequestMillis)
+	}
+	r.Recommended.MemoryRequestBytes = max(roundUp(int64(math.Ceil(peakMemory)), 1<<20), minMemoryRequest)
+	if r.Current.MemoryLimitBytes > 0 {
+		r.Recommended.MemoryLimitBytes = r.Recommended.MemoryRequestBytes
+	}
+
+	r.CPUStatus = provisioning(r.Current.CPURequestMillis, r.Recommended.CPURequestMillis)
+	r.MemoryStatus = provisioning(r.Current.MemoryRequestBytes, r.Recommended.MemoryRequestBytes)
+	return r
+}
+
+func provisioning(current, recommended int64) string {
+	switch {
+	case current == 0 || float64(current) < float64(recommended)/rightsizingTolerance:
+		return underProvisioned
+	case float64(current) > float64(recommended)*rightsizingTolerance:
+		return overProvisioned
+	default:
+		return rightsized
+	}
+}
+
+// percentile returns the nearest-rank percentile p of values.
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+func roundUp(value, step int64) int64 {
+	return (value + step - 1) / step * step
+}
+
+func rightsizingKey(namespace, workload, container string) string {
+	return namespace + "/" + workload + "/" + container
+}
+
+// podWorkload names pods without a controller after themselves.
+func podWorkload(workload string, pod string) string {
+	if workload == "" {
+		return "Pod/" + pod
+	}
+	return workload
+}
+
+// workloadAPIVersions lists the kinds patches are made for. Jobs are left out:
+// their pod template cannot be changed, and those of a CronJob are replaced on
+// every run.
+var workloadAPIVersions = map[string]string{
+	"Deployment":  "apps/v1",
+	"StatefulSet": "apps/v1",
+	"DaemonSet":   "apps/v1",
+	"ReplicaSet":  "apps/v1",
+}
+
+// patches turns the recommendations into strategic merge patches of the
+// workloads' pod templates, one per workload. Bare pods, workloads of unknown
+// kinds and containers without enough data are left out.
+func patches(recommendations []recommendation) []map[string]interface{} {
+	var result []map[string]interface{}
+	containers := make(map[string][]interface{})
+	var keys []string
+	for _, r := range recommendations {
+		kind, _, _ := strings.Cut(r.Workload, "/")
+		if _, ok := workloadAPIVersions[kind]; !ok || r.CPUStatus == insufficientData {
+			continue
+		}
+		key := r.Namespace + "/" + r.Workload
+		if _, ok := containers[key]; !ok {
+			keys = append(keys, key)
+		}
+		containers[key] = append(containers[key], map[string]interface{}{
+			"name":      r.Container,
+			"resources": r.Recommended.resourceRequirements(),
+		})
+	}
+
+	for _, key := range keys {
+		namespace, workload, _ := strings.Cut(key, "/")
+		kind, name, _ := strings.Cut(workload, "/")
+		result = append(result, map[string]interface{}{
+			"apiVersion": workloadAPIVersions[kind],
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{"containers": containers[key]},
+				},
+			},
+		})
+	}
+	return result
+}
+
+func (r resources) resourceRequirements() map[string]interface{} {
+	requirements := map[string]interface{}{
+		"requests": map[string]string{
+			"cpu":    resource.NewMilliQuantity(r.CPURequestMillis, resource.DecimalSI).String(),
+			"memory": resource.NewQuantity(r.MemoryRequestBytes, resource.BinarySI).String(),
+		},
+	}
+	limits := map[string]string{}
+	if r.CPULimitMillis > 0 {
+		limits["cpu"] = resource.NewMilliQuantity(r.CPULimitMillis, resource.DecimalSI).String()
+	}
+	if r.MemoryLimitBytes > 0 {
+		limits["memory"] = resource.NewQuantity(r.MemoryLimitBytes, resource.BinarySI).String()
+	}
+	if len(limits) > 0 {
+		requirements["limits"] = limits
+	}
+	return requirements
+}
+
+func (h *Handler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	window, err := rightsizingWindow(r)
+	vm := recommendationViewModel{Window: r.URL.Query().Get("window")}
+	if err == nil {
+		vm.Recommendations, err = h.service.GetRecommendations(r.Context(), r.URL.Query().Get("namespace"), window)
+	}
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "recommendations.html", vm)
+}
+
+func (h *Handler) GetRecommendationsJSON(w http.ResponseWriter, r *http.Request) {
+	window, err := rightsizingWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recommendations, err := h.service.GetRecommendations(r.Context(), r.URL.Query().Get("namespace"), window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recommendations)
+}
+
+// GetPatches serves the recommendations as patches to commit next to the
+// manifests: a YAML stream, or a JSON array with ?format=json.
+func (h *Handler) GetPatches(w http.ResponseWriter, r *http.Request) {
+	window, err := rightsizingWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recommendations, err := h.service.GetRecommendations(r.Context(), r.URL.Query().Get("namespace"), window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == JSON {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(patches(recommendations))
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	for i, patch := range patches(recommendations) {
+		data, err := yaml.Marshal(patch)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if i > 0 {
+			fmt.Fprintln(w, "---")
+		}
+		w.Write(data)
+	}
+}
+
+func rightsizingWindow(r *http.Request) (time.Duration, error) {
+	value := r.URL.Query().Get("window")
+	if value == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q: %w", value, err)
+	}
+	return window, nil
+}
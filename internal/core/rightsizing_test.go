@@ -0,0 +1,150 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Rightsizing(t *testing.T) {
+	pods := []kubeclient.Pod{
+		{Name: "web-1", Namespace: "team-a", Workload: "Deployment/web", Containers: []kubeclient.Container{
+			{Name: "app", CPURequest: 1000, CPULimit: 2000, MemoryRequest: 256 << 20, MemoryLimit: 256 << 20},
+		}},
+		{Name: "debug", Namespace: "team-b", Containers: []kubeclient.Container{
+			{Name: "shell", CPUUsage: 50, MemoryUsage: 10 << 20},
+		}},
+		{Name: "report-1", Namespace: "team-d", Workload: "Job/report-28512000", Containers: []kubeclient.Container{
+			{Name: "report", CPURequest: 100, MemoryRequest: 64 << 20},
+		}},
+		{Name: "idle-1", Namespace: "team-c", Workload: "Deployment/idle", Containers: []kubeclient.Container{
+			{Name: "app", CPURequest: 500, MemoryRequest: 128 << 20},
+		}},
+	}
+	var history []kubeclient.Sample
+	for i := 1; i <= 20; i++ {
+		history = append(history, kubeclient.Sample{Pods: []kubeclient.PodSample{
+			{Name: "web-1", Namespace: "team-a", Workload: "Deployment/web", Containers: []kubeclient.ContainerSample{
+				{Name: "app", CPUUsage: int64(i * 10), MemoryUsage: int64(i) * 10 << 20},
+			}},
+			{Name: "debug", Namespace: "team-b", Containers: []kubeclient.ContainerSample{
+				{Name: "shell", CPUUsage: 50, MemoryUsage: 10 << 20},
+			}},
+			{Name: "report-1", Namespace: "team-d", Workload: "Job/report-28512000", Containers: []kubeclient.ContainerSample{
+				{Name: "report", CPUUsage: 100, MemoryUsage: 64 << 20},
+			}},
+		}})
+	}
+	var since time.Time
+	kube := &core.KubeMock{
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return pods, nil
+		},
+		GetHistoryFunc: func(ctx context.Context, s time.Time) ([]kubeclient.Sample, error) {
+			since = s
+			return history, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given usage history, then recommend the cpu percentile and the memory peak with headroom", func(t *testing.T) {
+		recommendations, err := service.GetRecommendations(context.Background(), "team-a", 0)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, time.Now().Add(-24*time.Hour), since, time.Minute)
+		assert.Equal(t, 1, len(recommendations))
+
+		r := recommendations[0]
+		assert.Equal(t, "Deployment/web", r.Workload)
+		assert.Equal(t, "app", r.Container)
+		assert.Equal(t, 20, r.Samples)
+		assert.Equal(t, int64(190), r.Recommended.CPURequestMillis)
+		assert.Equal(t, int64(230), r.Recommended.CPULimitMillis)
+		assert.Equal(t, int64(230<<20), r.Recommended.MemoryRequestBytes)
+		assert.Equal(t, int64(230<<20), r.Recommended.MemoryLimitBytes)
+		assert.Equal(t, "over", r.CPUStatus)
+		assert.Equal(t, "ok", r.MemoryStatus)
+	})
+
+	t.Run("given a window, then look back that far", func(t *testing.T) {
+		_, err := service.GetRecommendations(context.Background(), "team-a", time.Hour)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), since, time.Minute)
+	})
+
+	t.Run("given a pod without requests, then flag it as under-provisioned", func(t *testing.T) {
+		recommendations, err := service.GetRecommendations(context.Background(), "team-b", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(recommendations))
+		assert.Equal(t, "Pod/debug", recommendations[0].Workload)
+		assert.Equal(t, "under", recommendations[0].CPUStatus)
+		assert.Equal(t, int64(0), recommendations[0].Recommended.CPULimitMillis)
+	})
+
+	t.Run("given a container without usage samples, then report insufficient data", func(t *testing.T) {
+		recommendations, err := service.GetRecommendations(context.Background(), "team-c", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(recommendations))
+		assert.Equal(t, 0, recommendations[0].Samples)
+		assert.Equal(t, "insufficient", recommendations[0].CPUStatus)
+		assert.Equal(t, "insufficient", recommendations[0].MemoryStatus)
+		assert.Equal(t, int64(0), recommendations[0].Recommended.CPURequestMillis)
+	})
+
+	t.Run("given a patch request, then patch the workloads only, not bare pods or jobs", func(t *testing.T) {
+		handler := core.NewHandler(nil, service)
+		rec := httptest.NewRecorder()
+		handler.GetPatches(rec, httptest.NewRequest(http.MethodGet, "/api/v1/recommendations/patches", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          limits:
+            cpu: 230m
+            memory: 230Mi
+          requests:
+            cpu: 190m
+            memory: 230Mi
+`, rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		handler.GetPatches(rec, httptest.NewRequest(http.MethodGet, "/api/v1/recommendations/patches?format=json", nil))
+		var patches []map[string]interface{}
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &patches))
+		assert.Equal(t, 1, len(patches))
+		assert.Equal(t, "Deployment", patches[0]["kind"])
+	})
+
+	t.Run("given an invalid window, then reject the request", func(t *testing.T) {
+		handler := core.NewHandler(nil, service)
+		rec := httptest.NewRecorder()
+		handler.GetRecommendationsJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/recommendations.json?window=week", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("given the page, then render the recommendations", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		req := httptest.NewRequest(http.MethodGet, "/recommendations?window=6h", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetRecommendations(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "team-a/Deployment/web")
+		assert.Contains(t, rec.Body.String(), "1000m → 190m")
+		assert.Contains(t, rec.Body.String(), "Not enough usage data")
+	})
+}
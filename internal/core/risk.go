@@ -0,0 +1,190 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+const (
+	riskHigh   = "high"
+	riskMedium = "medium"
+	riskLow    = "low"
+)
+
+// Memory usage, as a share of allocatable memory, from which a node is at
+// risk of memory pressure evictions.
+const (
+	memoryPressureHigh   = 0.9
+	memoryPressureMedium = 0.8
+)
+
+const maxEvictionCandidates = 5
+
+var riskOrder = map[string]int{riskHigh: 0, riskMedium: 1, riskLow: 2}
+
+type (
+	// nodeRisk is how overcommitted a node is, as ratios of the pods' requests,
+	// limits and usage to the node's allocatable resources.
+	nodeRisk struct {
+		Node               string              `json:"node"`
+		Level              string              `json:"level"`
+		Reasons            []string            `json:"reasons"`
+		CPURequestRatio    float64             `json:"cpuRequestRatio"`
+		CPULimitRatio      float64             `json:"cpuLimitRatio"`
+		MemoryRequestRatio float64             `json:"memoryRequestRatio"`
+		MemoryLimitRatio   float64             `json:"memoryLimitRatio"`
+		MemoryUsageRatio   float64             `json:"memoryUsageRatio"`
+		Evictions          []evictionCandidate `json:"evictions"`
+	}
+	evictionCandidate struct {
+		Name          string `json:"name"`
+		Namespace     string `json:"namespace"`
+		QOSClass      string `json:"qosClass"`
+		MemoryUsage   int64  `json:"memoryUsageBytes"`
+		MemoryRequest int64  `json:"memoryRequestBytes"`
+	}
+	riskViewModel struct {
+		Risks []nodeRisk
+		Error string
+	}
+)
+
+// GetRisks returns the risk of every visible node, riskiest first.
+func (s *Service) GetRisks(ctx context.Context) ([]nodeRisk, error) {
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visibleNodes, err := s.visibleNodes(ctx, access)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, err
+	}
+
+	podsByNode := scheduler.Running(pods)
+	risks := make([]nodeRisk, 0, len(nodes))
+	for _, n := range nodes {
+		if visibleNodes != nil && !visibleNodes[n.Name] {
+			continue
+		}
+		risks = append(risks, computeRisk(n, podsByNode[n.Name], access))
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		if riskOrder[risks[i].Level] != riskOrder[risks[j].Level] {
+			return riskOrder[risks[i].Level] < riskOrder[risks[j].Level]
+		}
+		return risks[i].MemoryUsageRatio > risks[j].MemoryUsageRatio
+	})
+	return risks, nil
+}
+
+// computeRisk rates a node by how close its memory usage is to allocatable,
+// where the kubelet starts evicting pods, and by whether the memory limits of
+// its pods add up to more than the node has. Overcommitted CPU limits only
+// throttle, so they are reported without raising the level. The eviction
+// candidates are the pods the kubelet would evict first, among those the user
+// may see.
+func computeRisk(n kubeclient.Node, pods []kubeclient.Pod, access *accessScope) nodeRisk {
+	risk := nodeRisk{Node: n.Name, Level: riskLow, Reasons: []string{}, Evictions: []evictionCandidate{}}
+	var cpuRequests, cpuLimits, memoryRequests, memoryLimits, memoryUsage int64
+	for _, p := range pods {
+		cpuRequests += p.CPURequest
+		cpuLimits += p.CPULimit
+		memoryRequests += p.MemoryRequest
+		memoryLimits += p.MemoryLimit
+		memoryUsage += p.MemoryUsage
+	}
+	risk.CPURequestRatio = ratio(cpuRequests, n.AvailableCPU)
+	risk.CPULimitRatio = ratio(cpuLimits, n.AvailableCPU)
+	risk.MemoryRequestRatio = ratio(memoryRequests, n.AllocatableMemory)
+	risk.MemoryLimitRatio = ratio(memoryLimits, n.AllocatableMemory)
+	risk.MemoryUsageRatio = ratio(memoryUsage, n.AllocatableMemory)
+
+	switch {
+	case risk.MemoryUsageRatio >= memoryPressureHigh:
+		risk.raise(riskHigh, fmt.Sprintf("memory usage at %.0f%% of allocatable", risk.MemoryUsageRatio*100))
+	case risk.MemoryUsageRatio >= memoryPressureMedium:
+		risk.raise(riskMedium, fmt.Sprintf("memory usage at %.0f%% of allocatable", risk.MemoryUsageRatio*100))
+	}
+	if risk.MemoryLimitRatio > 1 {
+		risk.raise(riskMedium, fmt.Sprintf("memory limits overcommitted %.2fx", risk.MemoryLimitRatio))
+	}
+	if risk.CPULimitRatio > 1 {
+		risk.raise(riskLow, fmt.Sprintf("CPU limits overcommitted %.2fx", risk.CPULimitRatio))
+	}
+
+	if risk.Level != riskLow {
+		for _, p := range evictionOrder(pods) {
+			if len(risk.Evictions) == maxEvictionCandidates {
+				break
+			}
+			if !access.namespace(p.Namespace) {
+				continue
+			}
+			risk.Evictions = append(risk.Evictions, evictionCandidate{
+				Name:          p.Name,
+				Namespace:     p.Namespace,
+				QOSClass:      p.QOSClass,
+				MemoryUsage:   p.MemoryUsage,
+				MemoryRequest: p.MemoryRequest,
+			})
+		}
+	}
+	return risk
+}
+
+func (r *nodeRisk) raise(level string, reason string) {
+	if riskOrder[level] < riskOrder[r.Level] {
+		r.Level = level
+	}
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// evictionOrder ranks the BestEffort and Burstable pods like the kubelet does
+// under memory pressure: pods using more memory than they request first, then
+// by lower priority, then by how far their usage exceeds their request.
+func evictionOrder(pods []kubeclient.Pod) []kubeclient.Pod {
+	var candidates []kubeclient.Pod
+	for _, p := range pods {
+		if p.QOSClass != "Guaranteed" {
+			candidates = append(candidates, p)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		aExceeds, bExceeds := a.MemoryUsage > a.MemoryRequest, b.MemoryUsage > b.MemoryRequest
+		if aExceeds != bExceeds {
+			return aExceeds
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.MemoryUsage-a.MemoryRequest > b.MemoryUsage-b.MemoryRequest
+	})
+	return candidates
+}
+
+func ratio(value, total int64) float64 {
+	return percent(value, total) / 100
+}
+
+func (h *Handler) GetRisks(w http.ResponseWriter, r *http.Request) {
+	risks, err := h.service.GetRisks(r.Context())
+	vm := riskViewModel{Risks: risks}
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "risks.html", vm)
+}
@@ -0,0 +1,87 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Risks(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{
+				{Name: "calm", AvailableCPU: 4000, AllocatableMemory: 8 << 30},
+				{Name: "busy", AvailableCPU: 4000, AllocatableMemory: 8 << 30},
+			}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "idle", Namespace: "team-a", Node: "calm", QOSClass: "Guaranteed",
+					CPURequest: 1000, CPULimit: 1000, MemoryRequest: 1 << 30, MemoryLimit: 1 << 30, MemoryUsage: 1 << 30},
+				{Name: "db", Namespace: "team-a", Node: "busy", QOSClass: "Guaranteed",
+					CPURequest: 2000, CPULimit: 2000, MemoryRequest: 4 << 30, MemoryLimit: 4 << 30, MemoryUsage: 4 << 30},
+				{Name: "cache", Namespace: "team-b", Node: "busy", QOSClass: "Burstable", Priority: 1000,
+					CPURequest: 500, CPULimit: 4000, MemoryRequest: 1 << 30, MemoryLimit: 6 << 30, MemoryUsage: 2 << 30},
+				{Name: "batch", Namespace: "team-b", Node: "busy", QOSClass: "BestEffort",
+					MemoryUsage: 1 << 30},
+				{Name: "web", Namespace: "team-b", Node: "busy", QOSClass: "Burstable",
+					MemoryRequest: 1 << 30, MemoryUsage: 512 << 20},
+				{Name: "report", Namespace: "team-a", Node: "calm", QOSClass: "Burstable", Phase: "Succeeded",
+					CPURequest: 4000, CPULimit: 8000, MemoryRequest: 4 << 30, MemoryLimit: 16 << 30},
+			}, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given memory usage near allocatable, then rate the node high risk first", func(t *testing.T) {
+		risks, err := service.GetRisks(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(risks))
+
+		busy := risks[0]
+		assert.Equal(t, "busy", busy.Node)
+		assert.Equal(t, "high", busy.Level)
+		assert.InDelta(t, 0.9375, busy.MemoryUsageRatio, 1e-9)
+		assert.InDelta(t, 1.25, busy.MemoryLimitRatio, 1e-9)
+		assert.InDelta(t, 1.5, busy.CPULimitRatio, 1e-9)
+		assert.Equal(t, []string{
+			"memory usage at 94% of allocatable",
+			"memory limits overcommitted 1.25x",
+			"CPU limits overcommitted 1.50x",
+		}, busy.Reasons)
+
+		var evictions []string
+		for _, e := range busy.Evictions {
+			evictions = append(evictions, e.Name)
+		}
+		assert.Equal(t, []string{"batch", "cache", "web"}, evictions)
+
+		assert.Equal(t, "calm", risks[1].Node)
+		assert.Equal(t, "low", risks[1].Level)
+		assert.InDelta(t, 0.125, risks[1].MemoryLimitRatio, 1e-9)
+		assert.Empty(t, risks[1].Evictions)
+	})
+
+	t.Run("given the nodes view, then carry the risk of each node", func(t *testing.T) {
+		nodes, err := service.GetNodes(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, "low", nodes[0].Risk.Level)
+		assert.Equal(t, "high", nodes[1].Risk.Level)
+	})
+
+	t.Run("given the risks page, then list the nodes", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		req := httptest.NewRequest(http.MethodGet, "/risks", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetRisks(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "team-b/batch")
+		assert.Contains(t, rec.Body.String(), "memory limits overcommitted 1.25x")
+	})
+}
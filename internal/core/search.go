@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+// Node sort orders. Utilization and pod count sort descending, age oldest
+// first; without a sort nodes keep the order they were added in.
+const (
+	SortByName   = "name"
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+	SortByPods   = "pods"
+	SortByAge    = "age"
+)
+
+var errUnknownSort = errors.New("unknown sort, use name, cpu, memory, pods or age")
+
+// NodeQuery sorts the nodes and searches their pods by name, namespace,
+// label or image.
+type NodeQuery struct {
+	Sort   string
+	Search string
+}
+
+// PodQuery colors the pods by a label, or by namespace when Label is empty,
+// and dims the pods not matching Search.
+type PodQuery struct {
+	Label  string
+	Search string
+}
+
+type (
+	nodeSummary struct {
+		Name              string `json:"name"`
+		Status            string `json:"status"`
+		Age               string `json:"age"`
+		Pods              int    `json:"pods"`
+		CPUAllocatable    int64  `json:"cpuAllocatableMillis"`
+		MemoryAllocatable int64  `json:"memoryAllocatableBytes"`
+		CPUUsage          int64  `json:"cpuUsageMillis"`
+		MemoryUsage       int64  `json:"memoryUsageBytes"`
+		// Utilization is the usage of the pods against the allocatable
+		// resources, in percent.
+		CPUUtilization    float64 `json:"cpuUtilization"`
+		MemoryUtilization float64 `json:"memoryUtilization"`
+		// Matches are the pods matching the search, when there is one.
+		Matches []podMatch `json:"matches,omitempty"`
+	}
+	podMatch struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}
+)
+
+// QueryNodes summarizes the visible nodes, sorted as asked, with the visible
+// pods matching the search.
+func (s *Service) QueryNodes(ctx context.Context, query NodeQuery) ([]nodeSummary, error) {
+	switch query.Sort {
+	case "", SortByName, SortByCPU, SortByMemory, SortByPods, SortByAge:
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownSort, query.Sort)
+	}
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visibleNodes, err := s.visibleNodes(ctx, access)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, err
+	}
+	// Finished pods are left out, like the pod counts of the node cards.
+	podsByNode := scheduler.Running(pods)
+
+	now := s.now()
+	result := make([]nodeSummary, 0, len(nodes))
+	created := make(map[string]int64, len(nodes))
+	for _, n := range nodes {
+		if visibleNodes != nil && !visibleNodes[n.Name] {
+			continue
+		}
+		summary := nodeSummary{
+			Name:              n.Name,
+			Status:            n.Status,
+			Pods:              len(podsByNode[n.Name]),
+			CPUAllocatable:    n.AvailableCPU,
+			MemoryAllocatable: n.AllocatableMemory,
+		}
+		if !n.Created.IsZero() {
+			summary.Age = age(now.Sub(n.Created))
+		}
+		created[n.Name] = n.Created.UnixNano()
+		for _, p := range podsByNode[n.Name] {
+			summary.CPUUsage += p.CPUUsage
+			summary.MemoryUsage += p.MemoryUsage
+			if query.Search != "" && access.namespace(p.Namespace) && podMatches(p, query.Search) {
+				summary.Matches = append(summary.Matches, podMatch{Name: p.Name, Namespace: p.Namespace})
+			}
+		}
+		summary.CPUUtilization = percent(summary.CPUUsage, n.AvailableCPU)
+		summary.MemoryUtilization = percent(summary.MemoryUsage, n.AllocatableMemory)
+		result = append(result, summary)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch query.Sort {
+		case SortByName:
+			return a.Name < b.Name
+		case SortByCPU:
+			return a.CPUUtilization > b.CPUUtilization
+		case SortByMemory:
+			return a.MemoryUtilization > b.MemoryUtilization
+		case SortByPods:
+			return a.Pods > b.Pods
+		case SortByAge:
+			return created[a.Name] < created[b.Name]
+		}
+		return false
+	})
+	return result, nil
+}
+
+// podMatches tells if the pod name, namespace, one of its labels as
+// key=value or one of its images contains the search, ignoring case.
+func podMatches(p kubeclient.Pod, search string) bool {
+	search = strings.ToLower(strings.TrimSpace(search))
+	if search == "" {
+		return true
+	}
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), search)
+	}
+	if contains(p.Name) || contains(p.Namespace) {
+		return true
+	}
+	for k, v := range p.Labels {
+		if contains(k + "=" + v) {
+			return true
+		}
+	}
+	for _, c := range p.Containers {
+		if contains(c.Image) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeQuery(r *http.Request) NodeQuery {
+	return NodeQuery{
+		Sort:   r.URL.Query().Get("sort"),
+		Search: r.URL.Query().Get("q"),
+	}
+}
+
+// GetNodesJSON serves the node summaries, sorted with ?sort= and with the
+// pods matching ?q=.
+func (h *Handler) GetNodesJSON(w http.ResponseWriter, r *http.Request) {
+	nodes, err := h.service.QueryNodes(r.Context(), nodeQuery(r))
+	if errors.Is(err, errUnknownSort) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nodes)
+}
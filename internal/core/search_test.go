@@ -0,0 +1,133 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Search(t *testing.T) {
+	now := time.Now()
+	kube := &core.KubeMock{
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{
+				{Name: "node-b", AvailableCPU: 1000, AllocatableMemory: 4 << 30, Created: now.Add(-2 * time.Hour)},
+				{Name: "node-c", AvailableCPU: 1000, AllocatableMemory: 4 << 30, Created: now.Add(-72 * time.Hour)},
+				{Name: "node-a", AvailableCPU: 1000, AllocatableMemory: 4 << 30, Created: now.Add(-30 * time.Minute)},
+			}, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			return kubeclient.Node{Name: name, AvailableCPU: 1000, AllocatableMemory: 4 << 30}, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			pods := []kubeclient.Pod{
+				{Name: "web", Namespace: "shop", Node: "node-b", CPUUsage: 800, MemoryUsage: 1 << 30,
+					Containers: []kubeclient.Container{{Name: "app", Image: "ghcr.io/acme/web:1.2"}}},
+				{Name: "cart", Namespace: "shop", Node: "node-b", CPUUsage: 100, MemoryUsage: 1 << 30,
+					Labels: map[string]string{"team": "checkout"}},
+				{Name: "ledger", Namespace: "payments", Node: "node-c", CPUUsage: 300, MemoryUsage: 3 << 30,
+					Labels: map[string]string{"team": "billing"}},
+				{Name: "report-1", Namespace: "payments", Node: "node-c", Phase: "Succeeded"},
+				{Name: "report-2", Namespace: "payments", Node: "node-c", Phase: "Failed"},
+			}
+			if node == "" {
+				return pods, nil
+			}
+			var onNode []kubeclient.Pod
+			for _, p := range pods {
+				if p.Node == node {
+					onNode = append(onNode, p)
+				}
+			}
+			return onNode, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given a sort, then order the nodes by it", func(t *testing.T) {
+		for sort, want := range map[string][]string{
+			"":       {"node-b", "node-c", "node-a"},
+			"name":   {"node-a", "node-b", "node-c"},
+			"cpu":    {"node-b", "node-c", "node-a"},
+			"memory": {"node-c", "node-b", "node-a"},
+			"pods":   {"node-b", "node-c", "node-a"},
+			"age":    {"node-c", "node-b", "node-a"},
+		} {
+			nodes, err := service.QueryNodes(context.Background(), core.NodeQuery{Sort: sort})
+			assert.Nil(t, err)
+			var names []string
+			for _, n := range nodes {
+				names = append(names, n.Name)
+			}
+			assert.Equal(t, want, names, sort)
+		}
+	})
+
+	t.Run("given finished pods, then leave them out of the pod counts", func(t *testing.T) {
+		nodes, err := service.QueryNodes(context.Background(), core.NodeQuery{Sort: "name"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, nodes[1].Pods)
+		assert.Equal(t, 1, nodes[2].Pods)
+	})
+
+	t.Run("given an unknown sort, then fail", func(t *testing.T) {
+		_, err := service.QueryNodes(context.Background(), core.NodeQuery{Sort: "size"})
+		assert.ErrorContains(t, err, `unknown sort`)
+	})
+
+	t.Run("given a search, then list the matching pods of every node", func(t *testing.T) {
+		nodes, err := service.QueryNodes(context.Background(), core.NodeQuery{Sort: "name", Search: "TEAM="})
+		assert.Nil(t, err)
+		assert.Empty(t, nodes[0].Matches)
+		assert.Equal(t, "cart", nodes[1].Matches[0].Name)
+		assert.Equal(t, "ledger", nodes[2].Matches[0].Name)
+
+		nodes, err = service.QueryNodes(context.Background(), core.NodeQuery{Sort: "name", Search: "acme/web"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(nodes[1].Matches))
+		assert.Equal(t, "web", nodes[1].Matches[0].Name)
+	})
+
+	t.Run("given a search, then dim the pods not matching it", func(t *testing.T) {
+		pods, err := service.QueryPods(context.Background(), "node-b", core.PodQuery{Search: "checkout"})
+		assert.Nil(t, err)
+		assert.True(t, pods[0].Dimmed)
+		assert.False(t, pods[1].Dimmed)
+	})
+
+	t.Run("given the nodes API, then serve the same query as JSON", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		rec := httptest.NewRecorder()
+		handler.GetNodesJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/nodes.json?sort=age&q=ledger", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var nodes []map[string]interface{}
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &nodes))
+		assert.Equal(t, "node-c", nodes[0]["name"])
+		assert.Equal(t, "3d", nodes[0]["age"])
+		assert.Equal(t, 75.0, nodes[0]["memoryUtilization"])
+		assert.Equal(t, "ledger", nodes[0]["matches"].([]interface{})[0].(map[string]interface{})["name"])
+
+		rec = httptest.NewRecorder()
+		handler.GetNodesJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/nodes.json?sort=size", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("given the nodes page with a search, then count the matches", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		rec := httptest.NewRecorder()
+		handler.GetNodes(rec, httptest.NewRequest(http.MethodGet, "/nodes?sort=pods&q=shop", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "2 matching pods")
+		assert.Contains(t, rec.Body.String(), `<option value="pods" selected>Pod count</option>`)
+	})
+}
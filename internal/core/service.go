@@ -3,13 +3,21 @@ package core
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
 )
 
 const (
-	CPU    string = "cpu"
-	Memory string = "memory"
+	CPU     string = "cpu"
+	Memory  string = "memory"
+	Storage string = "storage"
+	GPU     string = "gpu"
+	// EphemeralStorage and PodSlots size pods by their share of the node's
+	// ephemeral storage and maxPods.
+	EphemeralStorage string = "ephemeral-storage"
+	PodSlots         string = "pods"
 )
 
 //go:generate moq -rm -out kube_mock.go . Kube
@@ -18,16 +26,36 @@ type Kube interface {
 	GetPods(ctx context.Context, node string) ([]kubeclient.Pod, error)
 	GetNamespaces(ctx context.Context) ([]string, error)
 	GetNode(ctx context.Context, name string) (kubeclient.Node, error)
+	GetHistory(ctx context.Context, since time.Time) ([]kubeclient.Sample, error)
+	GetDisruptionBudgets(ctx context.Context) ([]kubeclient.DisruptionBudget, error)
+	GetEvents(ctx context.Context) ([]kubeclient.Event, error)
+	GetClaims(ctx context.Context) ([]kubeclient.Claim, error)
+	GetVolumes(ctx context.Context) ([]kubeclient.Volume, error)
+	GetStorageClasses(ctx context.Context) ([]kubeclient.StorageClass, error)
+	GetLargestNodeStorage(ctx context.Context) (int64, error)
 }
 
 type Service struct {
-	kube Kube
+	kube        Kube
+	authz       *authorization
+	pricing     *Pricing
+	rightsizing RightsizingOptions
+	alerting    Alerting
+	now         func() time.Time
 }
 
-func NewService(kube Kube) *Service {
-	return &Service{
-		kube: kube,
+type Option func(*Service)
+
+func NewService(kube Kube, opts ...Option) *Service {
+	s := &Service{
+		kube:        kube,
+		rightsizing: DefaultRightsizingOptions,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *Service) GetNamespaces(ctx context.Context) ([]namespace, error) {
@@ -35,7 +63,18 @@ func (s *Service) GetNamespaces(ctx context.Context) ([]namespace, error) {
 	if err != nil {
 		return nil, err
 	}
-	return toNamespacesModel(namespaces), nil
+
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if access.namespace(ns) {
+			visible = append(visible, ns)
+		}
+	}
+	return toNamespacesModel(visible), nil
 }
 
 func (s *Service) GetNodes(ctx context.Context) ([]node, error) {
@@ -44,17 +83,47 @@ func (s *Service) GetNodes(ctx context.Context) ([]node, error) {
 		return nil, err
 	}
 
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visibleNodes, err := s.visibleNodes(ctx, access)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, err
+	}
+	podsByNode := scheduler.Running(pods)
+
 	nodeResult := make([]node, 0, len(nodes))
 	for _, n := range nodes {
-		nodeResult = append(nodeResult, node{
-			Name: n.Name,
-			Info: fmt.Sprintf("CPU: %s | Mem: %s", cpuMilliToHumanReadable(n.AvailableCPU), memoryBytesToHumanReadable(n.AllocatableMemory)),
-		})
+		if visibleNodes != nil && !visibleNodes[n.Name] {
+			continue
+		}
+		model := node{
+			Name:        n.Name,
+			Info:        fmt.Sprintf("CPU: %s | Mem: %s", cpuMilliToHumanReadable(n.AvailableCPU), memoryBytesToHumanReadable(n.AllocatableMemory)),
+			CpuMillis:   n.AvailableCPU,
+			MemoryBytes: n.AllocatableMemory,
+			Risk:        computeRisk(n, podsByNode[n.Name], access),
+		}
+		nodeCapacity(&model, n, podsByNode[n.Name])
+		nodeResult = append(nodeResult, model)
 	}
 	return nodeResult, nil
 }
 
 func (s *Service) GetPods(ctx context.Context, n string) ([]pod, error) {
+	return s.QueryPods(ctx, n, PodQuery{})
+}
+
+// QueryPods returns the pods of the node grouped and colored by the value of
+// the query label, or by namespace when it is empty, with the pods not
+// matching the search dimmed.
+func (s *Service) QueryPods(ctx context.Context, n string, query PodQuery) ([]pod, error) {
 	pods, podErr := s.kube.GetPods(ctx, n)
 	if podErr != nil && pods == nil {
 		return nil, podErr
@@ -65,9 +134,36 @@ func (s *Service) GetPods(ctx context.Context, n string) ([]pod, error) {
 		return nil, err
 	}
 
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Events only add warnings to the pods, the pods are shown without them
+	// when the events cannot be watched.
+	events, _ := s.kube.GetEvents(ctx)
+	warnings := podWarnings(events)
+
+	storage, err := s.largestNodeStorage(ctx, pods)
+	if err != nil {
+		return nil, err
+	}
+
 	podResult := make([]pod, 0, len(pods))
 	for _, p := range pods {
-		podResult = append(podResult, toPodModel(p, node))
+		if !access.namespace(p.Namespace) {
+			continue
+		}
+		model := toPodModel(p, node)
+		setStorageSize(&model, p, storage)
+		if query.Label != "" {
+			model.Label = query.Label
+			model.Group = podGroup(p, query.Label)
+			model.Color = groupColor(model.Group)
+		}
+		model.Dimmed = !podMatches(p, query.Search)
+		model.Warnings = warnings[kubeclient.ObjectReference{Kind: "Pod", Namespace: p.Namespace, Name: p.Name}]
+		podResult = append(podResult, model)
 	}
 	return podResult, podErr
 }
@@ -84,14 +180,30 @@ func toPodModel(p kubeclient.Pod, n kubeclient.Node) pod {
 	}
 
 	return pod{
-		Name:        p.Name,
-		CpuSize:     fmt.Sprintf("%v%%", podCPU),
-		MemorySize:  fmt.Sprintf("%v%%", podMemory),
-		CpuUsage:    cpuMilliToHumanReadable(p.CPUUsage),
-		MemoryUsage: memoryBytesToHumanReadable(p.MemoryUsage),
-		Color:       namespaceByName(p.Namespace).Color,
-		Status:      p.Status,
-		Namespace:   p.Namespace,
+		Name:             p.Name,
+		CpuSize:          fmt.Sprintf("%v%%", podCPU),
+		MemorySize:       fmt.Sprintf("%v%%", podMemory),
+		CpuShare:         podCPU,
+		MemoryShare:      podMemory,
+		CpuMillis:        p.CPUUsage,
+		MemoryBytes:      p.MemoryUsage,
+		CpuUsage:         cpuMilliToHumanReadable(p.CPUUsage),
+		MemoryUsage:      memoryBytesToHumanReadable(p.MemoryUsage),
+		Color:            namespaceByName(p.Namespace).Color,
+		Group:            p.Namespace,
+		Status:           p.Status,
+		Ready:            p.Ready,
+		Namespace:        p.Namespace,
+		Restarts:         p.Restarts,
+		Trouble:          podTrouble(p),
+		StorageSize:      "0%",
+		StorageRequest:   memoryBytesToHumanReadable(p.StorageRequest),
+		StorageUsage:     memoryBytesToHumanReadable(p.StorageUsage),
+		GPUSize:          gpuSize(p, n),
+		GPUs:             gpus(p.ExtendedRequests),
+		EphemeralSize:    ephemeralSize(p, n),
+		EphemeralRequest: memoryBytesToHumanReadable(p.EphemeralStorageRequest),
+		SlotSize:         slotSize(p, n),
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/jawahars16/hawk8s/internal/auth"
 	"github.com/jawahars16/hawk8s/internal/core"
 	"github.com/jawahars16/hawk8s/internal/kubeclient"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +22,9 @@ func Test_GetPods(t *testing.T) {
 				assert.Equal(t, "node1", name)
 				return kubeclient.Node{}, nil
 			},
+			GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+				return nil, nil
+			},
 		}
 		service := core.NewService(kube)
 		pods, err := service.GetPods(context.Background(), "node1")
@@ -39,6 +43,9 @@ func Test_GetPods(t *testing.T) {
 				assert.Equal(t, "node1", name)
 				return kubeclient.Node{}, nil
 			},
+			GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+				return nil, nil
+			},
 		}
 		service := core.NewService(kube)
 		pods, err := service.GetPods(context.Background(), "node1")
@@ -56,6 +63,9 @@ func Test_GetPods(t *testing.T) {
 				assert.Equal(t, "node1", name)
 				return kubeclient.Node{}, nil
 			},
+			GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+				return nil, nil
+			},
 		}
 		service := core.NewService(kube)
 		pods, err := service.GetPods(context.Background(), "node1")
@@ -63,4 +73,117 @@ func Test_GetPods(t *testing.T) {
 		assert.Equal(t, 1, len(pods))
 		assert.Nil(t, err)
 	})
+
+	t.Run("given warning events about a pod, then mark the pod with them", func(t *testing.T) {
+		kube := &core.KubeMock{
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				return []kubeclient.Pod{{Name: "pod1", Namespace: "shop"}, {Name: "pod2", Namespace: "shop"}}, nil
+			},
+			GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+				return kubeclient.Node{}, nil
+			},
+			GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+				return []kubeclient.Event{
+					{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container", Object: kubeclient.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "pod1"}},
+					{Type: "Normal", Reason: "Pulled", Message: "Container image pulled", Object: kubeclient.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "pod2"}},
+				}, nil
+			},
+		}
+		pods, err := core.NewService(kube).GetPods(context.Background(), "node1")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"BackOff: Back-off restarting failed container"}, pods[0].Warnings)
+		assert.Empty(t, pods[1].Warnings)
+	})
+
+	t.Run("given events cannot be watched, then return the pods without warnings", func(t *testing.T) {
+		kube := &core.KubeMock{
+			GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+				return []kubeclient.Pod{{Name: "pod1", Namespace: "shop"}}, nil
+			},
+			GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+				return kubeclient.Node{}, nil
+			},
+			GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+				return nil, fmt.Errorf("events is forbidden")
+			},
+		}
+		pods, err := core.NewService(kube).GetPods(context.Background(), "node1")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(pods))
+		assert.Empty(t, pods[0].Warnings)
+	})
+}
+
+func Test_Authorization(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"team-a", "team-b"}, nil
+		},
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{{Name: "node1"}, {Name: "node2"}}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			pods := []kubeclient.Pod{
+				{Name: "pod-a", Namespace: "team-a", Node: "node1"},
+				{Name: "pod-b", Namespace: "team-b", Node: "node2"},
+			}
+			if node == "" {
+				return pods, nil
+			}
+			var result []kubeclient.Pod
+			for _, p := range pods {
+				if p.Node == node {
+					result = append(result, p)
+				}
+			}
+			return result, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			return kubeclient.Node{Name: name}, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+	}
+	authorizer := &core.AuthorizerMock{
+		CanListFunc: func(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+			return user == "alice" && resource == "pods" && namespace == "team-a", nil
+		},
+	}
+	service := core.NewService(kube, core.WithAuthorization(authorizer, nil, []string{"admins"}))
+	alice := auth.WithIdentity(context.Background(), auth.Identity{Name: "alice"})
+
+	t.Run("given a user allowed in one namespace, then only list that namespace", func(t *testing.T) {
+		namespaces, err := service.GetNamespaces(alice)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(namespaces))
+		assert.Equal(t, "team-a", namespaces[0].Name)
+	})
+
+	t.Run("given a user who cannot list nodes, then only list nodes running their pods", func(t *testing.T) {
+		nodes, err := service.GetNodes(alice)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(nodes))
+		assert.Equal(t, "node1", nodes[0].Name)
+	})
+
+	t.Run("given a user allowed in one namespace, then hide pods of other namespaces", func(t *testing.T) {
+		pods, err := service.GetPods(alice, "node2")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(pods))
+	})
+
+	t.Run("given an admin, then bypass the access reviews", func(t *testing.T) {
+		calls := len(authorizer.CanListCalls())
+		admin := auth.WithIdentity(context.Background(), auth.Identity{Name: "bob", Groups: []string{"admins"}})
+		nodes, err := service.GetNodes(admin)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(nodes))
+		assert.Equal(t, calls, len(authorizer.CanListCalls()))
+	})
+
+	t.Run("given no authenticated user, then return an error", func(t *testing.T) {
+		_, err := service.GetNamespaces(context.Background())
+		assert.Equal(t, core.ErrUnauthenticated, err)
+	})
 }
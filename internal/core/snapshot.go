@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	Table = "table"
+	JSON  = "json"
+	YAML  = "yaml"
+)
+
+type (
+	snapshot struct {
+		Time  time.Time      `json:"time"`
+		Nodes []nodeSnapshot `json:"nodes"`
+	}
+	nodeSnapshot struct {
+		Name                   string        `json:"name"`
+		Status                 string        `json:"status"`
+		AllocatableCPUMillis   int64         `json:"allocatableCPUMillis"`
+		AllocatableMemoryBytes int64         `json:"allocatableMemoryBytes"`
+		CPUUsageMillis         int64         `json:"cpuUsageMillis"`
+		MemoryUsageBytes       int64         `json:"memoryUsageBytes"`
+		CPUUtilization         float64       `json:"cpuUtilization"`
+		MemoryUtilization      float64       `json:"memoryUtilization"`
+		Pods                   []podSnapshot `json:"pods"`
+	}
+	podSnapshot struct {
+		Name             string `json:"name"`
+		Namespace        string `json:"namespace"`
+		Ready            string `json:"ready"`
+		Status           string `json:"status"`
+		CPUUsageMillis   int64  `json:"cpuUsageMillis"`
+		MemoryUsageBytes int64  `json:"memoryUsageBytes"`
+	}
+)
+
+// WriteSnapshot writes the current node and pod usage to w as a table, JSON or
+// YAML. A namespace other than "all" limits the pods to that namespace.
+func (s *Service) WriteSnapshot(ctx context.Context, w io.Writer, format string, namespace string) error {
+	snap, err := s.getSnapshot(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case Table, "":
+		return writeSnapshotTable(w, snap)
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(snap)
+	case YAML:
+		data, err := yaml.Marshal(snap)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func (s *Service) getSnapshot(ctx context.Context, namespace string) (snapshot, error) {
+	nodes, err := s.kube.GetNodes(ctx)
+	if err != nil {
+		return snapshot{}, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return snapshot{}, err
+	}
+	visibleNodes, err := s.visibleNodes(ctx, access)
+	if err != nil {
+		return snapshot{}, err
+	}
+
+	snap := snapshot{Time: time.Now().UTC(), Nodes: make([]nodeSnapshot, 0, len(nodes))}
+	for _, n := range nodes {
+		if visibleNodes != nil && !visibleNodes[n.Name] {
+			continue
+		}
+		pods, err := s.kube.GetPods(ctx, n.Name)
+		if err != nil && pods == nil {
+			return snapshot{}, err
+		}
+
+		ns := nodeSnapshot{
+			Name:                   n.Name,
+			Status:                 n.Status,
+			AllocatableCPUMillis:   n.AvailableCPU,
+			AllocatableMemoryBytes: n.AllocatableMemory,
+			Pods:                   make([]podSnapshot, 0, len(pods)),
+		}
+		for _, p := range pods {
+			if !access.namespace(p.Namespace) || (namespace != "" && namespace != "all" && namespace != p.Namespace) {
+				continue
+			}
+			ns.CPUUsageMillis += p.CPUUsage
+			ns.MemoryUsageBytes += p.MemoryUsage
+			ns.Pods = append(ns.Pods, podSnapshot{
+				Name:             p.Name,
+				Namespace:        p.Namespace,
+				Ready:            p.Ready,
+				Status:           p.Status,
+				CPUUsageMillis:   p.CPUUsage,
+				MemoryUsageBytes: p.MemoryUsage,
+			})
+		}
+		ns.CPUUtilization = percent(ns.CPUUsageMillis, ns.AllocatableCPUMillis)
+		ns.MemoryUtilization = percent(ns.MemoryUsageBytes, ns.AllocatableMemoryBytes)
+		snap.Nodes = append(snap.Nodes, ns)
+	}
+	return snap, nil
+}
+
+func writeSnapshotTable(w io.Writer, snap snapshot) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tSTATUS\tPODS\tCPU\tCPU%\tMEMORY\tMEMORY%")
+	for _, n := range snap.Nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s/%s\t%.0f%%\t%s/%s\t%.0f%%\n", n.Name, n.Status, len(n.Pods),
+			cpuMilliToHumanReadable(n.CPUUsageMillis), cpuMilliToHumanReadable(n.AllocatableCPUMillis), n.CPUUtilization,
+			memoryBytesToHumanReadable(n.MemoryUsageBytes), memoryBytesToHumanReadable(n.AllocatableMemoryBytes), n.MemoryUtilization)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NAMESPACE\tPOD\tREADY\tNODE\tSTATUS\tCPU\tMEMORY")
+	for _, n := range snap.Nodes {
+		for _, p := range n.Pods {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Namespace, p.Name, p.Ready, n.Name, p.Status,
+				cpuMilliToHumanReadable(p.CPUUsageMillis), memoryBytesToHumanReadable(p.MemoryUsageBytes))
+		}
+	}
+	return tw.Flush()
+}
+
+func percent(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total) * 100
+}
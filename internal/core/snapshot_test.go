@@ -0,0 +1,73 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_WriteSnapshot(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{{Name: "node1", Status: "Ready", AvailableCPU: 2000, AllocatableMemory: 4 << 30}}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "pod-a", Namespace: "team-a", Node: node, Status: "Running", Ready: "1/1", CPUUsage: 500, MemoryUsage: 1 << 30},
+				{Name: "pod-b", Namespace: "team-b", Node: node, Status: "Running", Ready: "1/1", CPUUsage: 500, MemoryUsage: 1 << 30},
+			}, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given json output, then sum pod usage per node", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Nil(t, service.WriteSnapshot(context.Background(), &out, core.JSON, "all"))
+
+		var snap struct {
+			Nodes []struct {
+				Name              string
+				CPUUsageMillis    int64
+				CPUUtilization    float64
+				MemoryUtilization float64
+				Pods              []struct{ Name string }
+			}
+		}
+		assert.Nil(t, json.Unmarshal(out.Bytes(), &snap))
+		assert.Equal(t, 1, len(snap.Nodes))
+		assert.Equal(t, int64(1000), snap.Nodes[0].CPUUsageMillis)
+		assert.Equal(t, 50.0, snap.Nodes[0].CPUUtilization)
+		assert.Equal(t, 50.0, snap.Nodes[0].MemoryUtilization)
+		assert.Equal(t, 2, len(snap.Nodes[0].Pods))
+	})
+
+	t.Run("given a namespace, then only include its pods", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Nil(t, service.WriteSnapshot(context.Background(), &out, core.YAML, "team-a"))
+
+		var snap map[string]interface{}
+		assert.Nil(t, yaml.Unmarshal(out.Bytes(), &snap))
+		pods := snap["nodes"].([]interface{})[0].(map[string]interface{})["pods"].([]interface{})
+		assert.Equal(t, 1, len(pods))
+	})
+
+	t.Run("given table output, then print a node and a pod table", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Nil(t, service.WriteSnapshot(context.Background(), &out, core.Table, "all"))
+		lines := strings.Split(out.String(), "\n")
+		assert.Equal(t, []string{"NODE", "STATUS", "PODS", "CPU", "CPU%", "MEMORY", "MEMORY%"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"node1", "Ready", "2", "1/2", "50%", "2Gi/4Gi", "50%"}, strings.Fields(lines[1]))
+		assert.Equal(t, []string{"team-a", "pod-a", "1/1", "node1", "Running", "500m", "1Gi"}, strings.Fields(lines[4]))
+	})
+
+	t.Run("given an unknown format, then return an error", func(t *testing.T) {
+		assert.NotNil(t, service.WriteSnapshot(context.Background(), &bytes.Buffer{}, "xml", "all"))
+	})
+}
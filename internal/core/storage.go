@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+)
+
+const (
+	claimPending = "Pending"
+	claimLost    = "Lost"
+)
+
+type (
+	storageViewModel struct {
+		Namespaces []namespaceStorage
+		Claims     []storageClaim
+		// Volumes are the PersistentVolumes not bound to a claim, shown only
+		// to users who can see every node.
+		Volumes []storageVolume
+		Classes []kubeclient.StorageClass
+		// Unbound is the number of pending and lost claims.
+		Unbound int
+		// Warning is why some of the claims or their usage may be missing.
+		Warning string
+		Error   string
+	}
+	namespaceStorage struct {
+		Name      string
+		Color     string
+		Claims    int
+		Unbound   int
+		Requested string
+		Used      string
+	}
+	storageClaim struct {
+		Name         string
+		Namespace    string
+		Color        string
+		Phase        string
+		Unbound      bool
+		StorageClass string
+		Volume       string
+		AccessModes  string
+		Request      string
+		Capacity     string
+		// Used is empty when no kubelet reported stats of the volume.
+		Used        string
+		UsedPercent float64
+		Pods        []string
+		Age         string
+	}
+	storageVolume struct {
+		Name          string
+		Phase         string
+		StorageClass  string
+		Capacity      string
+		ReclaimPolicy string
+		// Claim is the claim a released volume was bound to.
+		Claim string
+	}
+)
+
+// GetStorage lists the visible claims with the pods mounting them, pending
+// and lost claims first, the storage requested and used per namespace, the
+// volumes not bound to a claim and the storage classes.
+func (s *Service) GetStorage(ctx context.Context) (storageViewModel, error) {
+	var vm storageViewModel
+	claims, err := s.kube.GetClaims(ctx)
+	if err != nil && len(claims) == 0 {
+		return vm, err
+	}
+	if err != nil {
+		vm.Warning = err.Error()
+	}
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return vm, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return vm, err
+	}
+
+	mounts := make(map[string][]string)
+	for _, p := range pods {
+		for _, c := range p.Claims {
+			key := p.Namespace + "/" + c
+			mounts[key] = append(mounts[key], p.Name)
+		}
+	}
+
+	type totals struct {
+		claims, unbound int
+		requested, used int64
+	}
+	namespaces := make(map[string]*totals)
+	now := s.now()
+	for _, c := range claims {
+		if !access.namespace(c.Namespace) {
+			continue
+		}
+		claim := storageClaim{
+			Name:         c.Name,
+			Namespace:    c.Namespace,
+			Color:        namespaceByName(c.Namespace).Color,
+			Phase:        c.Phase,
+			Unbound:      c.Phase == claimPending || c.Phase == claimLost,
+			StorageClass: c.StorageClass,
+			Volume:       c.Volume,
+			AccessModes:  strings.Join(c.AccessModes, ", "),
+			Request:      memoryBytesToHumanReadable(c.Request),
+			Pods:         mounts[c.Namespace+"/"+c.Name],
+			Age:          age(now.Sub(c.Created)),
+		}
+		if c.Capacity > 0 {
+			claim.Capacity = memoryBytesToHumanReadable(c.Capacity)
+		}
+		if c.HasStats {
+			claim.Used = memoryBytesToHumanReadable(c.Used)
+			claim.UsedPercent = percent(c.Used, c.Used+c.Available)
+		}
+		vm.Claims = append(vm.Claims, claim)
+
+		t, ok := namespaces[c.Namespace]
+		if !ok {
+			t = &totals{}
+			namespaces[c.Namespace] = t
+		}
+		t.claims++
+		t.requested += c.Request
+		t.used += c.Used
+		if claim.Unbound {
+			t.unbound++
+			vm.Unbound++
+		}
+	}
+	sort.SliceStable(vm.Claims, func(i, j int) bool {
+		return vm.Claims[i].Unbound && !vm.Claims[j].Unbound
+	})
+
+	for name, t := range namespaces {
+		vm.Namespaces = append(vm.Namespaces, namespaceStorage{
+			Name:      name,
+			Color:     namespaceByName(name).Color,
+			Claims:    t.claims,
+			Unbound:   t.unbound,
+			Requested: memoryBytesToHumanReadable(t.requested),
+			Used:      memoryBytesToHumanReadable(t.used),
+		})
+	}
+	sort.Slice(vm.Namespaces, func(i, j int) bool { return vm.Namespaces[i].Name < vm.Namespaces[j].Name })
+
+	// PersistentVolumes are cluster scoped, like nodes.
+	if access == nil || access.allNodes {
+		volumes, err := s.kube.GetVolumes(ctx)
+		if err != nil && len(volumes) == 0 {
+			return vm, err
+		}
+		for _, v := range volumes {
+			if v.Phase == "Bound" {
+				continue
+			}
+			volume := storageVolume{
+				Name:          v.Name,
+				Phase:         v.Phase,
+				StorageClass:  v.StorageClass,
+				Capacity:      memoryBytesToHumanReadable(v.Capacity),
+				ReclaimPolicy: v.ReclaimPolicy,
+			}
+			if v.ClaimName != "" {
+				volume.Claim = v.ClaimNamespace + "/" + v.ClaimName
+			}
+			vm.Volumes = append(vm.Volumes, volume)
+		}
+	}
+
+	vm.Classes, err = s.kube.GetStorageClasses(ctx)
+	if err != nil && len(vm.Classes) == 0 {
+		return vm, err
+	}
+	return vm, nil
+}
+
+// largestNodeStorage is the most storage claimed by the pods of a node, which
+// storage mode sizes the pods by, as nodes have no storage capacity. It is 0
+// when none of the pods claim storage.
+func (s *Service) largestNodeStorage(ctx context.Context, pods []kubeclient.Pod) (int64, error) {
+	claimed := false
+	for _, p := range pods {
+		claimed = claimed || p.StorageRequest > 0
+	}
+	if !claimed {
+		return 0, nil
+	}
+	return s.kube.GetLargestNodeStorage(ctx)
+}
+
+func setStorageSize(model *pod, p kubeclient.Pod, largest int64) {
+	if p.StorageRequest == 0 || largest == 0 {
+		return
+	}
+	model.StorageSize = fmt.Sprintf("%v%%", max(float32(percent(p.StorageRequest, largest)), 0.5))
+}
+
+func (h *Handler) GetStorage(w http.ResponseWriter, r *http.Request) {
+	vm, err := h.service.GetStorage(r.Context())
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "storage.html", vm)
+}
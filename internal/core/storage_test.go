@@ -0,0 +1,135 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Storage(t *testing.T) {
+	now := time.Now()
+	var claimsErr error
+	pods := []kubeclient.Pod{
+		{Name: "db-0", Namespace: "shop", Node: "node-1", Claims: []string{"data-db-0"}, StorageRequest: 10 << 30, StorageUsage: 3 << 30},
+		{Name: "web", Namespace: "shop", Node: "node-1"},
+		{Name: "ledger-0", Namespace: "payments", Node: "node-2", Claims: []string{"data-ledger-0"}, StorageRequest: 20 << 30},
+	}
+	kube := &core.KubeMock{
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			if node == "" {
+				return pods, nil
+			}
+			var result []kubeclient.Pod
+			for _, p := range pods {
+				if p.Node == node {
+					result = append(result, p)
+				}
+			}
+			return result, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			return kubeclient.Node{Name: name, AvailableCPU: 1000, AllocatableMemory: 4 << 30}, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+		GetClaimsFunc: func(ctx context.Context) ([]kubeclient.Claim, error) {
+			return []kubeclient.Claim{
+				{Name: "data-ledger-0", Namespace: "payments", Phase: "Bound", StorageClass: "standard", Request: 20 << 30, Created: now.Add(-48 * time.Hour)},
+				{Name: "data-db-0", Namespace: "shop", Phase: "Bound", StorageClass: "standard", AccessModes: []string{"ReadWriteOnce"}, Request: 10 << 30, Capacity: 10 << 30, Used: 3 << 30, Available: 7 << 30, HasStats: true, Created: now.Add(-time.Hour)},
+				{Name: "uploads", Namespace: "shop", Phase: "Pending", StorageClass: "fast", Request: 5 << 30, Created: now.Add(-time.Minute)},
+			}, claimsErr
+		},
+		GetLargestNodeStorageFunc: func(ctx context.Context) (int64, error) {
+			return 20 << 30, nil
+		},
+		GetVolumesFunc: func(ctx context.Context) ([]kubeclient.Volume, error) {
+			return []kubeclient.Volume{
+				{Name: "pv-1", Phase: "Bound", Capacity: 10 << 30, ClaimNamespace: "shop", ClaimName: "data-db-0"},
+				{Name: "pv-2", Phase: "Released", Capacity: 50 << 30, ReclaimPolicy: "Retain", ClaimNamespace: "shop", ClaimName: "old"},
+			}, nil
+		},
+		GetStorageClassesFunc: func(ctx context.Context) ([]kubeclient.StorageClass, error) {
+			return []kubeclient.StorageClass{{Name: "standard", Provisioner: "ebs.csi.aws.com", Default: true}}, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given claims, then list them unbound first with the pods mounting them", func(t *testing.T) {
+		vm, err := service.GetStorage(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, vm.Unbound)
+		assert.Equal(t, 3, len(vm.Claims))
+		assert.Equal(t, "uploads", vm.Claims[0].Name)
+		assert.True(t, vm.Claims[0].Unbound)
+		assert.Equal(t, "", vm.Claims[0].Used)
+		assert.Equal(t, "data-ledger-0", vm.Claims[1].Name)
+		assert.Equal(t, []string{"ledger-0"}, vm.Claims[1].Pods)
+
+		db := vm.Claims[2]
+		assert.Equal(t, []string{"db-0"}, db.Pods)
+		assert.Equal(t, "10Gi", db.Request)
+		assert.Equal(t, "3Gi", db.Used)
+		assert.InDelta(t, 30.0, db.UsedPercent, 0.01)
+		assert.Equal(t, "1h", db.Age)
+	})
+
+	t.Run("given claims, then total them per namespace", func(t *testing.T) {
+		vm, err := service.GetStorage(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(vm.Namespaces))
+		assert.Equal(t, "payments", vm.Namespaces[0].Name)
+		shop := vm.Namespaces[1]
+		assert.Equal(t, 2, shop.Claims)
+		assert.Equal(t, 1, shop.Unbound)
+		assert.Equal(t, "15Gi", shop.Requested)
+		assert.Equal(t, "3Gi", shop.Used)
+	})
+
+	t.Run("given volumes, then list only the unbound ones", func(t *testing.T) {
+		vm, err := service.GetStorage(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(vm.Volumes))
+		assert.Equal(t, "pv-2", vm.Volumes[0].Name)
+		assert.Equal(t, "shop/old", vm.Volumes[0].Claim)
+		assert.Equal(t, "standard", vm.Classes[0].Name)
+	})
+
+	t.Run("given storage mode, then size pods by their claims against the node claiming the most", func(t *testing.T) {
+		result, err := service.GetPods(context.Background(), "node-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "50%", result[0].StorageSize)
+		assert.Equal(t, "10Gi", result[0].StorageRequest)
+		assert.Equal(t, "3Gi", result[0].StorageUsage)
+		assert.Equal(t, "0%", result[1].StorageSize)
+	})
+
+	t.Run("given volume stats that cannot be read, then show the claims with a warning", func(t *testing.T) {
+		claimsErr = errors.New("volume stats of node node-1: forbidden")
+		defer func() { claimsErr = nil }()
+		vm, err := service.GetStorage(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(vm.Claims))
+		assert.Equal(t, "volume stats of node node-1: forbidden", vm.Warning)
+	})
+
+	t.Run("given the storage page, then render the claims", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		req := httptest.NewRequest(http.MethodGet, "/storage", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetStorage(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "1 unbound claims")
+		assert.Contains(t, rec.Body.String(), "shop/data-db-0")
+		assert.Contains(t, rec.Body.String(), "3Gi (30%)")
+		assert.Contains(t, rec.Body.String(), "Unbound volumes")
+	})
+}
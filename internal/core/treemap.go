@@ -0,0 +1,385 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+)
+
+// Treemap hierarchies and what the rectangles are sized by.
+const (
+	ByNamespace = "namespace"
+	ByNode      = "node"
+	Usage       = "usage"
+	Requests    = "requests"
+)
+
+const (
+	treemapWidth  = 1000
+	treemapHeight = 600
+	// treemapHeader is the room left for the name on top of a rectangle with
+	// nested rectangles.
+	treemapHeader  = 18
+	treemapPadding = 2
+	// treemapCharWidth estimates the width of a character of the labels, to
+	// leave out labels that do not fit.
+	treemapCharWidth = 7
+	treemapNodeFill  = "#CBD5E1"
+)
+
+// TreemapQuery selects the hierarchy, cluster → namespace → workload → pod or
+// cluster → node → pod, the resource and measure the rectangles are sized by,
+// and the path drilled down to.
+type TreemapQuery struct {
+	By      string
+	Mode    string
+	Measure string
+	Path    []string
+}
+
+type (
+	treemapViewModel struct {
+		Query  TreemapQuery
+		Width  int
+		Height int
+		Total  string
+		Rects  []treemapRect
+		// Crumbs lead back up the drill-down path, the last one is the
+		// current level.
+		Crumbs   []viewLink
+		By       []viewLink
+		Modes    []viewLink
+		Measures []viewLink
+		SVG      string
+		Error    string
+	}
+	// viewLink is a link to another view of a page, like another hierarchy
+	// or resource.
+	viewLink struct {
+		Name   string
+		Href   string
+		Active bool
+	}
+	treemapRect struct {
+		Name      string
+		Title     string
+		Href      string
+		X, Y      float64
+		Width     float64
+		Height    float64
+		Fill      string
+		TextColor string
+		ShowLabel bool
+		Nested    bool
+	}
+	treemapNode struct {
+		name      string
+		namespace string
+		value     int64
+		href      string
+		children  []*treemapNode
+		index     map[string]*treemapNode
+	}
+)
+
+func (n *treemapNode) child(name, namespace string) *treemapNode {
+	if c, ok := n.index[name]; ok {
+		return c
+	}
+	if n.index == nil {
+		n.index = make(map[string]*treemapNode)
+	}
+	c := &treemapNode{name: name, namespace: namespace}
+	n.index[name] = c
+	n.children = append(n.children, c)
+	return c
+}
+
+// GetTreemap lays out the visible pods as a treemap of the level the query
+// drilled down to, two levels deep.
+func (s *Service) GetTreemap(ctx context.Context, query TreemapQuery) (treemapViewModel, error) {
+	if query.By != ByNode {
+		query.By = ByNamespace
+	}
+	if query.Mode != Memory {
+		query.Mode = CPU
+	}
+	if query.Measure != Requests {
+		query.Measure = Usage
+	}
+	vm := treemapViewModel{Query: query, Width: treemapWidth, Height: treemapHeight}
+	vm.By = []viewLink{
+		{Name: "Namespace", Href: treemapURL(TreemapQuery{By: ByNamespace, Mode: query.Mode, Measure: query.Measure}), Active: query.By == ByNamespace},
+		{Name: "Node", Href: treemapURL(TreemapQuery{By: ByNode, Mode: query.Mode, Measure: query.Measure}), Active: query.By == ByNode},
+	}
+	vm.Modes = []viewLink{
+		{Name: "CPU", Href: treemapURL(TreemapQuery{By: query.By, Mode: CPU, Measure: query.Measure, Path: query.Path}), Active: query.Mode == CPU},
+		{Name: "Memory", Href: treemapURL(TreemapQuery{By: query.By, Mode: Memory, Measure: query.Measure, Path: query.Path}), Active: query.Mode == Memory},
+	}
+	vm.Measures = []viewLink{
+		{Name: "Usage", Href: treemapURL(TreemapQuery{By: query.By, Mode: query.Mode, Measure: Usage, Path: query.Path}), Active: query.Measure == Usage},
+		{Name: "Requests", Href: treemapURL(TreemapQuery{By: query.By, Mode: query.Mode, Measure: Requests, Path: query.Path}), Active: query.Measure == Requests},
+	}
+	vm.SVG = treemapURL(query) + "&format=svg"
+
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return vm, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return vm, err
+	}
+
+	root := &treemapNode{name: "Cluster"}
+	for _, p := range pods {
+		// Finished pods keep their requests but no longer hold them.
+		if !access.namespace(p.Namespace) || scheduler.Finished(p) {
+			continue
+		}
+		value := treemapValue(p, query)
+		if value <= 0 {
+			continue
+		}
+		var path []*treemapNode
+		leaf := fmt.Sprintf("%s/%s", p.Namespace, p.Name)
+		switch query.By {
+		case ByNode:
+			if p.Node == "" {
+				continue
+			}
+			n := root.child(p.Node, "")
+			path = []*treemapNode{root, n, n.child(leaf, p.Namespace)}
+		default:
+			name := p.Workload
+			if name == "" {
+				name = "Pod/" + p.Name
+			}
+			ns := root.child(p.Namespace, p.Namespace)
+			workload := ns.child(name, p.Namespace)
+			path = []*treemapNode{root, ns, workload, workload.child(leaf, p.Namespace)}
+		}
+		for _, n := range path {
+			n.value += value
+		}
+		path[len(path)-1].name = p.Name
+		path[len(path)-1].href = fmt.Sprintf("/events?%s", url.Values{"kind": {"Pod"}, "namespace": {p.Namespace}, "name": {p.Name}}.Encode())
+	}
+
+	focus := root
+	vm.Crumbs = []viewLink{{Name: root.name, Href: treemapURL(TreemapQuery{By: query.By, Mode: query.Mode, Measure: query.Measure})}}
+	for i, name := range query.Path {
+		next, ok := focus.index[name]
+		if !ok || len(next.children) == 0 {
+			vm.Error = fmt.Sprintf("%s not found", name)
+			return vm, nil
+		}
+		focus = next
+		up := TreemapQuery{By: query.By, Mode: query.Mode, Measure: query.Measure, Path: query.Path[:i+1]}
+		vm.Crumbs = append(vm.Crumbs, viewLink{Name: name, Href: treemapURL(up)})
+	}
+	vm.Crumbs[len(vm.Crumbs)-1].Active = true
+	vm.Total = treemapFormat(focus.value, query.Mode)
+
+	children := sortedChildren(focus)
+	rects := squarify(values(children), 0, 0, treemapWidth, treemapHeight)
+	for i, c := range children {
+		r := rects[i]
+		rect := treemapRect{
+			Name:   c.name,
+			Title:  fmt.Sprintf("%s | %s", c.name, treemapFormat(c.value, query.Mode)),
+			Href:   c.href,
+			X:      r.x,
+			Y:      r.y,
+			Width:  r.w,
+			Height: r.h,
+		}
+		if len(c.children) > 0 {
+			rect.Href = treemapURL(TreemapQuery{By: query.By, Mode: query.Mode, Measure: query.Measure, Path: append(append([]string{}, query.Path...), c.name)})
+		}
+		rect.Fill, rect.TextColor = treemapFill(c)
+		rect.ShowLabel = r.w >= float64(len(c.name)*treemapCharWidth+2*treemapPadding) && r.h >= treemapHeader
+		vm.Rects = append(vm.Rects, rect)
+
+		// The children of the rectangle are nested under its name.
+		inner := sortedChildren(c)
+		if len(inner) == 0 || r.w <= 2*treemapPadding || r.h <= treemapHeader+treemapPadding {
+			continue
+		}
+		for j, ir := range squarify(values(inner), r.x+treemapPadding, r.y+treemapHeader, r.w-2*treemapPadding, r.h-treemapHeader-treemapPadding) {
+			ic := inner[j]
+			nested := treemapRect{
+				Name:   ic.name,
+				Title:  fmt.Sprintf("%s | %s | %s", c.name, ic.name, treemapFormat(ic.value, query.Mode)),
+				Href:   rect.Href,
+				X:      ir.x,
+				Y:      ir.y,
+				Width:  ir.w,
+				Height: ir.h,
+				Nested: true,
+			}
+			if len(ic.children) > 0 {
+				nested.Href = treemapURL(TreemapQuery{By: query.By, Mode: query.Mode, Measure: query.Measure, Path: append(append([]string{}, query.Path...), c.name, ic.name)})
+			} else if ic.href != "" {
+				nested.Href = ic.href
+			}
+			nested.Fill, nested.TextColor = treemapFill(ic)
+			nested.ShowLabel = ir.w >= float64(len(ic.name)*treemapCharWidth+2*treemapPadding) && ir.h >= treemapHeader
+			vm.Rects = append(vm.Rects, nested)
+		}
+	}
+	return vm, nil
+}
+
+func treemapValue(p kubeclient.Pod, query TreemapQuery) int64 {
+	switch {
+	case query.Mode == Memory && query.Measure == Requests:
+		return p.MemoryRequest
+	case query.Mode == Memory:
+		return p.MemoryUsage
+	case query.Measure == Requests:
+		return p.CPURequest
+	default:
+		return p.CPUUsage
+	}
+}
+
+func treemapFormat(value int64, mode string) string {
+	if mode == Memory {
+		return memoryBytesToHumanReadable(value)
+	}
+	return cpuMilliToHumanReadable(value)
+}
+
+// treemapFill colors everything in a namespace with the namespace color and
+// nodes, which span namespaces, gray.
+func treemapFill(n *treemapNode) (string, string) {
+	if n.namespace == "" {
+		return treemapNodeFill, "#0F172A"
+	}
+	return namespaceByName(n.namespace).Color, "#FFFFFF"
+}
+
+func treemapURL(query TreemapQuery) string {
+	values := url.Values{
+		"by":      {query.By},
+		"mode":    {query.Mode},
+		"measure": {query.Measure},
+	}
+	if len(query.Path) > 0 {
+		values["path"] = query.Path
+	}
+	return "/treemap?" + values.Encode()
+}
+
+func sortedChildren(n *treemapNode) []*treemapNode {
+	children := append([]*treemapNode{}, n.children...)
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].value != children[j].value {
+			return children[i].value > children[j].value
+		}
+		return children[i].name < children[j].name
+	})
+	return children
+}
+
+func values(nodes []*treemapNode) []float64 {
+	result := make([]float64, len(nodes))
+	for i, n := range nodes {
+		result[i] = float64(n.value)
+	}
+	return result
+}
+
+type layoutRect struct {
+	x, y, w, h float64
+}
+
+// squarify lays values, sorted from the largest, out in the rectangle with
+// areas proportional to the values, keeping the rectangles as square as it
+// can. See Bruls, Huizing and van Wijk, Squarified Treemaps.
+func squarify(values []float64, x, y, w, h float64) []layoutRect {
+	result := make([]layoutRect, len(values))
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	if total <= 0 || w <= 0 || h <= 0 {
+		return result
+	}
+	areas := make([]float64, len(values))
+	for i, v := range values {
+		areas[i] = v / total * w * h
+	}
+
+	for start := 0; start < len(areas); {
+		short := min(w, h)
+		end, sum := start+1, areas[start]
+		for end < len(areas) && worst(areas[start:end+1], sum+areas[end], short) <= worst(areas[start:end], sum, short) {
+			sum += areas[end]
+			end++
+		}
+		// The row goes along the short side, taking the thickness its area
+		// needs.
+		thickness := sum / short
+		offset := 0.0
+		for i := start; i < end; i++ {
+			length := areas[i] / thickness
+			if w >= h {
+				result[i] = layoutRect{x: x, y: y + offset, w: thickness, h: length}
+			} else {
+				result[i] = layoutRect{x: x + offset, y: y, w: length, h: thickness}
+			}
+			offset += length
+		}
+		if w >= h {
+			x, w = x+thickness, w-thickness
+		} else {
+			y, h = y+thickness, h-thickness
+		}
+		start = end
+	}
+	return result
+}
+
+// worst is the largest aspect ratio of the row of areas laid along side.
+func worst(row []float64, sum float64, side float64) float64 {
+	largest, smallest := row[0], row[0]
+	for _, a := range row {
+		largest = max(largest, a)
+		smallest = min(smallest, a)
+	}
+	s2, side2 := sum*sum, side*side
+	return max(side2*largest/s2, s2/(side2*smallest))
+}
+
+func (h *Handler) GetTreemap(w http.ResponseWriter, r *http.Request) {
+	query := TreemapQuery{
+		By:      r.URL.Query().Get("by"),
+		Mode:    r.URL.Query().Get("mode"),
+		Measure: r.URL.Query().Get("measure"),
+		Path:    r.URL.Query()["path"],
+	}
+	vm, err := h.service.GetTreemap(r.Context(), query)
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	if r.URL.Query().Get("format") == SVG {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		if r.URL.Query().Get("download") != "" {
+			name := fmt.Sprintf("hawk8s-treemap-%s.svg", time.Now().UTC().Format("20060102-150405"))
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		}
+		if err := h.tmpl.ExecuteTemplate(w, "treemap.svg", vm); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	h.render(w, r, "treemap.html", vm)
+}
@@ -0,0 +1,118 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Treemap(t *testing.T) {
+	kube := &core.KubeMock{
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "web-1", Namespace: "shop", Node: "node-1", Workload: "Deployment/web", CPUUsage: 300, CPURequest: 100, MemoryUsage: 1 << 30},
+				{Name: "web-2", Namespace: "shop", Node: "node-2", Workload: "Deployment/web", CPUUsage: 300, CPURequest: 100, MemoryUsage: 1 << 30},
+				{Name: "cart", Namespace: "shop", Node: "node-1", Workload: "Deployment/cart", CPUUsage: 200, CPURequest: 500},
+				{Name: "ledger", Namespace: "payments", Node: "node-2", Workload: "StatefulSet/ledger", CPUUsage: 200, CPURequest: 300},
+				{Name: "idle", Namespace: "payments", Node: "node-2", Workload: "Deployment/idle"},
+				{Name: "pending", Namespace: "payments", Workload: "Deployment/pending", CPUUsage: 0, CPURequest: 100},
+				{Name: "report", Namespace: "payments", Node: "node-1", Workload: "Job/report", CPURequest: 400, Phase: "Succeeded"},
+			}, nil
+		},
+	}
+	service := core.NewService(kube)
+
+	t.Run("given the cluster, then size namespaces by CPU usage with workloads nested", func(t *testing.T) {
+		vm, err := service.GetTreemap(context.Background(), core.TreemapQuery{})
+		assert.Nil(t, err)
+		assert.Equal(t, "1", vm.Total)
+		assert.Equal(t, "Cluster", vm.Crumbs[0].Name)
+
+		var top []string
+		var shopArea, paymentsArea, nestedArea float64
+		for _, r := range vm.Rects {
+			assert.True(t, r.X >= 0 && r.Y >= 0 && r.X+r.Width <= 1000.01 && r.Y+r.Height <= 600.01, r.Name)
+			if r.Nested {
+				if r.Name == "Deployment/web" {
+					nestedArea += r.Width * r.Height
+					assert.Contains(t, r.Href, "path=shop&path=Deployment%2Fweb")
+				}
+				continue
+			}
+			top = append(top, r.Name)
+			switch r.Name {
+			case "shop":
+				shopArea = r.Width * r.Height
+				assert.Contains(t, r.Href, "path=shop")
+			case "payments":
+				paymentsArea = r.Width * r.Height
+			}
+		}
+		assert.Equal(t, []string{"shop", "payments"}, top)
+		assert.InDelta(t, 4.0, shopArea/paymentsArea, 0.01)
+		assert.True(t, nestedArea > 0)
+	})
+
+	t.Run("given a path, then drill down to the pods of the workload", func(t *testing.T) {
+		vm, err := service.GetTreemap(context.Background(), core.TreemapQuery{Path: []string{"shop", "Deployment/web"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Cluster", "shop", "Deployment/web"}, []string{vm.Crumbs[0].Name, vm.Crumbs[1].Name, vm.Crumbs[2].Name})
+		assert.True(t, vm.Crumbs[2].Active)
+		assert.Equal(t, 2, len(vm.Rects))
+		assert.Equal(t, "web-1", vm.Rects[0].Name)
+		assert.Equal(t, "/events?kind=Pod&name=web-1&namespace=shop", vm.Rects[0].Href)
+		assert.InDelta(t, 300000, vm.Rects[0].Width*vm.Rects[0].Height, 0.01)
+	})
+
+	t.Run("given the node hierarchy by requests, then nest the pods in their nodes", func(t *testing.T) {
+		vm, err := service.GetTreemap(context.Background(), core.TreemapQuery{By: "node", Measure: "requests"})
+		assert.Nil(t, err)
+		var nodes, pods []string
+		for _, r := range vm.Rects {
+			if r.Nested {
+				pods = append(pods, r.Name)
+			} else {
+				nodes = append(nodes, r.Name)
+			}
+		}
+		assert.Equal(t, []string{"node-1", "node-2"}, nodes)
+		assert.Equal(t, []string{"cart", "web-1", "ledger", "web-2"}, pods)
+	})
+
+	t.Run("given finished pods, then leave their requests out", func(t *testing.T) {
+		vm, err := service.GetTreemap(context.Background(), core.TreemapQuery{Measure: "requests", Path: []string{"payments"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "400m", vm.Total)
+		for _, r := range vm.Rects {
+			assert.NotEqual(t, "Job/report", r.Name)
+		}
+	})
+
+	t.Run("given an unknown path, then say so", func(t *testing.T) {
+		vm, err := service.GetTreemap(context.Background(), core.TreemapQuery{Path: []string{"missing"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "missing not found", vm.Error)
+	})
+
+	t.Run("given the treemap page, then render it as SVG", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), service)
+		req := httptest.NewRequest(http.MethodGet, "/treemap?by=namespace&mode=memory", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetTreemap(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "<svg")
+		assert.Contains(t, rec.Body.String(), "<title>shop | 2Gi</title>")
+
+		rec = httptest.NewRecorder()
+		handler.GetTreemap(rec, httptest.NewRequest(http.MethodGet, "/treemap?format=svg&download=1", nil))
+		assert.Equal(t, "image/svg+xml", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+		assert.Contains(t, rec.Body.String(), "<svg")
+	})
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+)
+
+// troubleWindow is how far back restarts count as recent.
+const troubleWindow = time.Hour
+
+const (
+	troubleCrashLoop = "CrashLoopBackOff"
+	troubleOOMKilled = "OOMKilled"
+)
+
+type (
+	troubledViewModel struct {
+		Pods  []troubledPod
+		Error string
+	}
+	troubledPod struct {
+		Name      string
+		Namespace string
+		Node      string
+		Workload  string
+		Color     string
+		// Trouble is CrashLoopBackOff or OOMKilled when the pod is in that
+		// state.
+		Trouble        string
+		Restarts       int32
+		RecentRestarts int32
+		// Container is the container restarting the most, with its state and
+		// last termination.
+		Container      string
+		State          string
+		LastReason     string
+		LastExitCode   int32
+		LastTerminated string
+	}
+)
+
+// GetTroubledPods lists the pods that restarted in the last hour, are crash
+// looping or were OOM killed, most recent restarts first. Recent restarts are
+// counted from the usage history, so without history none are recent.
+func (s *Service) GetTroubledPods(ctx context.Context) ([]troubledPod, error) {
+	pods, err := s.kube.GetPods(ctx, "")
+	if err != nil && pods == nil {
+		return nil, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	history, err := s.kube.GetHistory(ctx, now.Add(-troubleWindow))
+	if err != nil {
+		return nil, err
+	}
+	restarts := kubeclient.RecentRestarts(history, pods)
+
+	var result []troubledPod
+	for _, p := range pods {
+		if !access.namespace(p.Namespace) {
+			continue
+		}
+		recent := restarts[p.Namespace+"/"+p.Name]
+		trouble := podTrouble(p)
+		if recent == 0 && trouble == "" {
+			continue
+		}
+		row := troubledPod{
+			Name:           p.Name,
+			Namespace:      p.Namespace,
+			Node:           p.Node,
+			Workload:       p.Workload,
+			Color:          namespaceByName(p.Namespace).Color,
+			Trouble:        trouble,
+			Restarts:       p.Restarts,
+			RecentRestarts: recent,
+		}
+		if c, ok := restartingContainer(p); ok {
+			row.Container = c.Name
+			row.State = c.State
+			if c.StateReason != "" {
+				row.State += " (" + c.StateReason + ")"
+			}
+			row.LastReason = c.LastTerminationReason
+			row.LastExitCode = c.LastExitCode
+			if !c.LastTerminated.IsZero() {
+				row.LastTerminated = age(now.Sub(c.LastTerminated))
+			}
+		}
+		result = append(result, row)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].RecentRestarts != result[j].RecentRestarts {
+			return result[i].RecentRestarts > result[j].RecentRestarts
+		}
+		if result[i].Restarts != result[j].Restarts {
+			return result[i].Restarts > result[j].Restarts
+		}
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// podTrouble tells if a container of the pod is crash looping or was last
+// OOM killed, crash looping first.
+func podTrouble(p kubeclient.Pod) string {
+	trouble := ""
+	for _, c := range p.Containers {
+		if c.StateReason == troubleCrashLoop {
+			return troubleCrashLoop
+		}
+		if c.LastTerminationReason == troubleOOMKilled {
+			trouble = troubleOOMKilled
+		}
+	}
+	return trouble
+}
+
+func restartingContainer(p kubeclient.Pod) (kubeclient.Container, bool) {
+	var result kubeclient.Container
+	found := false
+	for _, c := range p.Containers {
+		if !found || c.Restarts > result.Restarts {
+			result, found = c, true
+		}
+	}
+	return result, found
+}
+
+func (h *Handler) GetTroubledPods(w http.ResponseWriter, r *http.Request) {
+	pods, err := h.service.GetTroubledPods(r.Context())
+	vm := troubledViewModel{Pods: pods}
+	if err != nil {
+		vm.Error = err.Error()
+	}
+	h.render(w, r, "troubled.html", vm)
+}
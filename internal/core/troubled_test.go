@@ -0,0 +1,71 @@
+package core_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TroubledPods(t *testing.T) {
+	kube := &core.KubeMock{
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "healthy", Namespace: "shop", Restarts: 0, Containers: []kubeclient.Container{{Name: "app", State: "Running"}}},
+				{Name: "flapping", Namespace: "shop", Restarts: 12, Containers: []kubeclient.Container{{Name: "app", State: "Running", Restarts: 12}}},
+				{Name: "crashing", Namespace: "shop", Restarts: 3, Containers: []kubeclient.Container{
+					{Name: "sidecar", State: "Running"},
+					{Name: "app", State: "Waiting", StateReason: "CrashLoopBackOff", Restarts: 3, LastTerminationReason: "Error", LastExitCode: 1},
+				}},
+				{Name: "oom", Namespace: "shop", Restarts: 1, Containers: []kubeclient.Container{
+					{Name: "app", State: "Running", Restarts: 1, LastTerminationReason: "OOMKilled", LastExitCode: 137},
+				}},
+				{Name: "old", Namespace: "shop", Restarts: 4, Containers: []kubeclient.Container{{Name: "app", State: "Running", Restarts: 4}}},
+			}, nil
+		},
+		GetHistoryFunc: func(ctx context.Context, since time.Time) ([]kubeclient.Sample, error) {
+			return []kubeclient.Sample{
+				{Pods: []kubeclient.PodSample{{Name: "flapping", Namespace: "shop", Restarts: 2}, {Name: "old", Namespace: "shop", Restarts: 4}}},
+				{Pods: []kubeclient.PodSample{{Name: "flapping", Namespace: "shop", Restarts: 8}}},
+			}, nil
+		},
+	}
+
+	t.Run("given restarting pods, then list them by restarts since their first sample", func(t *testing.T) {
+		pods, err := core.NewService(kube).GetTroubledPods(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(pods))
+
+		assert.Equal(t, "flapping", pods[0].Name)
+		assert.Equal(t, int32(10), pods[0].RecentRestarts)
+		assert.Empty(t, pods[0].Trouble)
+
+		assert.Equal(t, "crashing", pods[1].Name)
+		assert.Equal(t, "CrashLoopBackOff", pods[1].Trouble)
+		assert.Equal(t, "app", pods[1].Container)
+		assert.Equal(t, "Waiting (CrashLoopBackOff)", pods[1].State)
+		assert.Equal(t, int32(0), pods[1].RecentRestarts)
+		assert.Equal(t, int32(1), pods[1].LastExitCode)
+
+		assert.Equal(t, "oom", pods[2].Name)
+		assert.Equal(t, "OOMKilled", pods[2].Trouble)
+		assert.Equal(t, int32(137), pods[2].LastExitCode)
+	})
+
+	t.Run("given the troubled pods page, then render the pods", func(t *testing.T) {
+		handler := core.NewHandler(parseTemplates(t), core.NewService(kube))
+		req := httptest.NewRequest(http.MethodGet, "/troubled", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.GetTroubledPods(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "shop/crashing")
+		assert.Contains(t, rec.Body.String(), "OOMKilled, exit code 137")
+		assert.NotContains(t, rec.Body.String(), "shop/healthy")
+	})
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jawahars16/hawk8s/internal/xlsx"
+)
+
+var usageColumns = []interface{}{
+	"pod", "namespace", "node", "workload", "phase",
+	"cpu_usage_millicores", "memory_usage_bytes",
+	"cpu_request_millicores", "cpu_limit_millicores",
+	"memory_request_bytes", "memory_limit_bytes",
+}
+
+// GetUsageRows flattens the pods into spreadsheet rows, starting with a header
+// row. Like the UI, namespace "all" and an empty node select everything. A
+// limit of 0 means the pod is not limited.
+func (s *Service) GetUsageRows(ctx context.Context, namespace string, node string) ([][]interface{}, error) {
+	pods, err := s.kube.GetPods(ctx, node)
+	if err != nil && pods == nil {
+		return nil, err
+	}
+	access, err := s.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]interface{}{usageColumns}
+	for _, p := range pods {
+		if !access.namespace(p.Namespace) || (namespace != "" && namespace != "all" && namespace != p.Namespace) {
+			continue
+		}
+		rows = append(rows, []interface{}{
+			p.Name, p.Namespace, p.Node, p.Workload, p.Phase,
+			p.CPUUsage, p.MemoryUsage,
+			p.CPURequest, p.CPULimit,
+			p.MemoryRequest, p.MemoryLimit,
+		})
+	}
+	return rows, nil
+}
+
+func (h *Handler) GetUsageCSV(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.service.GetUsageRows(r.Context(), r.URL.Query().Get("namespace"), r.URL.Query().Get("node"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", usageFileName("csv")))
+
+	writer := csv.NewWriter(w)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = fmt.Sprint(v)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+}
+
+func (h *Handler) GetUsageXLSX(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.service.GetUsageRows(r.Context(), r.URL.Query().Get("namespace"), r.URL.Query().Get("node"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", usageFileName("xlsx")))
+	if err := xlsx.Write(w, "Usage", rows); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func usageFileName(extension string) string {
+	return fmt.Sprintf("hawk8s-usage-%s.%s", time.Now().UTC().Format("20060102-150405"), extension)
+}
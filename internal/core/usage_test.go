@@ -0,0 +1,63 @@
+package core_test
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Usage(t *testing.T) {
+	kube := &core.KubeMock{
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			pods := []kubeclient.Pod{
+				{Name: "web-1", Namespace: "team-a", Node: "node1", Workload: "Deployment/web", Phase: "Running",
+					CPUUsage: 120, MemoryUsage: 64 << 20, CPURequest: 250, CPULimit: 500, MemoryRequest: 128 << 20},
+				{Name: "db-0", Namespace: "team-b", Node: "node2", Workload: "StatefulSet/db", Phase: "Running"},
+			}
+			if node == "" {
+				return pods, nil
+			}
+			var result []kubeclient.Pod
+			for _, p := range pods {
+				if p.Node == node {
+					result = append(result, p)
+				}
+			}
+			return result, nil
+		},
+	}
+	handler := core.NewHandler(nil, core.NewService(kube))
+
+	t.Run("given a namespace filter, then export only its pods as csv", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.GetUsageCSV(rec, httptest.NewRequest(http.MethodGet, "/api/v1/export.csv?namespace=team-a", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(records))
+		assert.Equal(t, "pod", records[0][0])
+		assert.Equal(t, []string{"web-1", "team-a", "node1", "Deployment/web", "Running", "120", "67108864", "250", "500", "134217728", "0"}, records[1])
+	})
+
+	t.Run("given a node filter, then export only the pods on that node", func(t *testing.T) {
+		rows, err := core.NewService(kube).GetUsageRows(context.Background(), "all", "node2")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(rows))
+		assert.Equal(t, "db-0", rows[1][0])
+	})
+
+	t.Run("given an xlsx request, then serve a workbook", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.GetUsageXLSX(rec, httptest.NewRequest(http.MethodGet, "/api/v1/export.xlsx", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "PK", rec.Body.String()[:2])
+	})
+}
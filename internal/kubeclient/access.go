@@ -0,0 +1,51 @@
+package kubeclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AccessReviewer answers whether a user may list a resource by asking the API
+// server with a SubjectAccessReview. Answers are cached per user, resource and
+// namespace for the validity of the cache.
+type AccessReviewer struct {
+	client kubernetes.Interface
+	cache  cache
+}
+
+func NewAccessReviewer(client kubernetes.Interface, cache cache) *AccessReviewer {
+	return &AccessReviewer{
+		client: client,
+		cache:  cache,
+	}
+}
+
+func (a *AccessReviewer) CanList(ctx context.Context, user string, groups []string, resource string, namespace string) (bool, error) {
+	key := fmt.Sprintf("%s|%s|%s|%s", user, strings.Join(groups, ","), resource, namespace)
+	if allowed, ok := a.cache.Get(key); ok {
+		return allowed.(bool), nil
+	}
+
+	review, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user,
+			Groups: groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:      "list",
+				Resource:  resource,
+				Namespace: namespace,
+			},
+		},
+	}, v1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	a.cache.Set(key, review.Status.Allowed)
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,74 @@
+package kubeclient_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/cache"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// reviewReactor allows listing pods in the namespaces given and counts the
+// reviews it answered.
+func reviewReactor(calls *int, namespaces ...string) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*calls++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		for _, ns := range namespaces {
+			if attributes.Verb == "list" && attributes.Resource == "pods" && attributes.Namespace == ns {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	}
+}
+
+func Test_AccessReviewer(t *testing.T) {
+	t.Run("given the review allows the namespace, then the user can list", func(t *testing.T) {
+		calls := 0
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "subjectaccessreviews", reviewReactor(&calls, "team-a"))
+		reviewer := kubeclient.NewAccessReviewer(client, cache.New(60))
+
+		allowed, err := reviewer.CanList(context.Background(), "alice", []string{"dev"}, "pods", "team-a")
+		assert.Nil(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = reviewer.CanList(context.Background(), "alice", []string{"dev"}, "pods", "team-b")
+		assert.Nil(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("given a repeated review, then answer it from the cache", func(t *testing.T) {
+		calls := 0
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "subjectaccessreviews", reviewReactor(&calls, "team-a"))
+		reviewer := kubeclient.NewAccessReviewer(client, cache.New(60))
+
+		for i := 0; i < 3; i++ {
+			allowed, err := reviewer.CanList(context.Background(), "alice", nil, "pods", "team-a")
+			assert.Nil(t, err)
+			assert.True(t, allowed)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("given the review fails, then return the error", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("forbidden")
+		})
+		reviewer := kubeclient.NewAccessReviewer(client, cache.New(60))
+
+		allowed, err := reviewer.CanList(context.Background(), "alice", nil, "pods", "team-a")
+		assert.NotNil(t, err)
+		assert.False(t, allowed)
+	})
+}
@@ -0,0 +1,141 @@
+package kubeclient
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+)
+
+const (
+	defaultEventsPerObject = 20
+	// defaultEventRetention matches the default event TTL of the API server.
+	defaultEventRetention = time.Hour
+	// eventPruneInterval is how often the objects past retention are dropped.
+	eventPruneInterval = time.Minute
+)
+
+// events keeps the latest events of every object. prune drops the objects
+// without an event for longer than retention.
+type events struct {
+	objects   map[ObjectReference][]Event
+	perObject int
+	retention time.Duration
+}
+
+func (e *events) add(event Event) {
+	if e.objects == nil {
+		e.objects = make(map[ObjectReference][]Event)
+	}
+	history := e.objects[event.Object]
+	replaced := false
+	for i := range history {
+		if history[i].UID == event.UID {
+			history[i] = event
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		history = append(history, event)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Last.Before(history[j].Last)
+	})
+	if len(history) > e.perObject {
+		history = history[len(history)-e.perObject:]
+	}
+	e.objects[event.Object] = history
+}
+
+func (e *events) prune(now time.Time) {
+	cutoff := now.Add(-e.retention)
+	for object, history := range e.objects {
+		if history[len(history)-1].Last.Before(cutoff) {
+			delete(e.objects, object)
+		}
+	}
+}
+
+// all returns the events of every object, newest first.
+func (e *events) all() []Event {
+	var result []Event
+	for _, history := range e.objects {
+		result = append(result, history...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].Last.Equal(result[j].Last) {
+			return result[i].Last.After(result[j].Last)
+		}
+		return result[i].UID < result[j].UID
+	})
+	return result
+}
+
+func toEvent(e *corev1.Event) Event {
+	event := Event{
+		UID:     string(e.UID),
+		Type:    e.Type,
+		Reason:  e.Reason,
+		Message: e.Message,
+		Object: ObjectReference{
+			Kind:      e.InvolvedObject.Kind,
+			Namespace: e.InvolvedObject.Namespace,
+			Name:      e.InvolvedObject.Name,
+		},
+		Source: e.Source.Component,
+		Count:  e.Count,
+		First:  e.FirstTimestamp.Time,
+		Last:   e.LastTimestamp.Time,
+	}
+	if event.Source == "" {
+		event.Source = e.ReportingController
+	}
+	if e.Series != nil {
+		event.Count = e.Series.Count
+		event.Last = e.Series.LastObservedTime.Time
+	}
+	return withDefaults(event, e.EventTime.Time)
+}
+
+func toEventV1(e *eventsv1.Event) Event {
+	event := Event{
+		UID:     string(e.UID),
+		Type:    e.Type,
+		Reason:  e.Reason,
+		Message: e.Note,
+		Object: ObjectReference{
+			Kind:      e.Regarding.Kind,
+			Namespace: e.Regarding.Namespace,
+			Name:      e.Regarding.Name,
+		},
+		Source: e.ReportingController,
+		Count:  e.DeprecatedCount,
+		First:  e.DeprecatedFirstTimestamp.Time,
+		Last:   e.DeprecatedLastTimestamp.Time,
+	}
+	if event.Source == "" {
+		event.Source = e.DeprecatedSource.Component
+	}
+	if e.Series != nil {
+		event.Count = e.Series.Count
+		event.Last = e.Series.LastObservedTime.Time
+	}
+	return withDefaults(event, e.EventTime.Time)
+}
+
+// withDefaults fills the times of events that only carry an event time, as
+// reported by newer components.
+func withDefaults(event Event, eventTime time.Time) Event {
+	if event.First.IsZero() {
+		event.First = eventTime
+	}
+	if event.Last.IsZero() {
+		event.Last = event.First
+	}
+	if event.Count == 0 {
+		event.Count = 1
+	}
+	return event
+}
@@ -0,0 +1,149 @@
+package kubeclient
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultHistoryRetention = 24 * time.Hour
+	defaultHistoryInterval  = time.Minute
+	// defaultHistoryPodSamples bounds the pod samples kept across the whole
+	// history, about 350 pods at full resolution over a day.
+	defaultHistoryPodSamples = 500000
+)
+
+// history keeps a sample of the cluster at most every interval, dropping the
+// samples older than retention. Finished pods hold no resources and are not
+// sampled. When the samples hold more than maxPodSamples pod samples, every
+// other sample is dropped and the spacing doubled, so the history keeps
+// covering the retention evenly at a coarser resolution.
+type history struct {
+	samples       []Sample
+	retention     time.Duration
+	interval      time.Duration
+	maxPodSamples int
+	// step is the spacing the history was thinned to, at least interval.
+	step time.Duration
+}
+
+func (h *history) record(now time.Time, nodes []Node, pods []Pod) {
+	if h.retention <= 0 {
+		return
+	}
+	if n := len(h.samples); n > 0 && now.Sub(h.samples[n-1].Time) < max(h.interval, h.step) {
+		return
+	}
+
+	sample := Sample{
+		Time:  now,
+		Nodes: make([]NodeSample, 0, len(nodes)),
+		Pods:  make([]PodSample, 0, len(pods)),
+	}
+	for _, n := range nodes {
+		sample.Nodes = append(sample.Nodes, NodeSample{
+			Name:              n.Name,
+			Labels:            n.Labels,
+			TotalCPU:          n.TotalCPU,
+			TotalMemory:       n.TotalMemory,
+			AvailableCPU:      n.AvailableCPU,
+			AllocatableMemory: n.AllocatableMemory,
+		})
+	}
+	for _, p := range pods {
+		if p.Phase == string(corev1.PodSucceeded) || p.Phase == string(corev1.PodFailed) {
+			continue
+		}
+		containers := make([]ContainerSample, 0, len(p.Containers))
+		for _, c := range p.Containers {
+			containers = append(containers, ContainerSample{
+				Name:        c.Name,
+				CPUUsage:    c.CPUUsage,
+				MemoryUsage: c.MemoryUsage,
+			})
+		}
+		sample.Pods = append(sample.Pods, PodSample{
+			Name:          p.Name,
+			Namespace:     p.Namespace,
+			Node:          p.Node,
+			Workload:      p.Workload,
+			Labels:        p.Labels,
+			CPUUsage:      p.CPUUsage,
+			MemoryUsage:   p.MemoryUsage,
+			CPURequest:    p.CPURequest,
+			MemoryRequest: p.MemoryRequest,
+			Restarts:      p.Restarts,
+			Containers:    containers,
+		})
+	}
+	h.samples = append(h.samples, sample)
+
+	cutoff := now.Add(-h.retention)
+	i := 0
+	for i < len(h.samples) && h.samples[i].Time.Before(cutoff) {
+		i++
+	}
+	h.samples = h.samples[i:]
+	h.thin()
+}
+
+// thin drops every other sample, keeping the newest, until the pod samples
+// fit in maxPodSamples.
+func (h *history) thin() {
+	if h.maxPodSamples <= 0 {
+		return
+	}
+	for len(h.samples) > 1 && h.podSamples() > h.maxPodSamples {
+		kept := h.samples[:0]
+		for i := (len(h.samples) - 1) % 2; i < len(h.samples); i += 2 {
+			kept = append(kept, h.samples[i])
+		}
+		clear(h.samples[len(kept):])
+		h.samples = kept
+		h.step = 2 * max(h.interval, h.step)
+	}
+}
+
+func (h *history) podSamples() int {
+	total := 0
+	for _, s := range h.samples {
+		total += len(s.Pods)
+	}
+	return total
+}
+
+func (h *history) since(t time.Time) []Sample {
+	samples := make([]Sample, 0, len(h.samples))
+	for _, s := range h.samples {
+		if !s.Time.Before(t) {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// RecentRestarts returns how often each pod restarted since its earliest
+// sample in samples, keyed by namespace/name. Pods without a sample have no
+// baseline and are left out, so restarts from before the history never count
+// as recent. A pod recreated under the same name counts no restarts until it
+// passes its predecessor's count.
+func RecentRestarts(samples []Sample, pods []Pod) map[string]int32 {
+	baseline := make(map[string]int32)
+	for _, sample := range samples {
+		for _, p := range sample.Pods {
+			key := p.Namespace + "/" + p.Name
+			if _, ok := baseline[key]; !ok {
+				baseline[key] = p.Restarts
+			}
+		}
+	}
+	recent := make(map[string]int32)
+	for _, p := range pods {
+		key := p.Namespace + "/" + p.Name
+		if before, ok := baseline[key]; ok {
+			recent[key] = max(p.Restarts-before, 0)
+		}
+	}
+	return recent
+}
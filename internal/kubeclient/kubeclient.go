@@ -2,8 +2,9 @@ package kubeclient
 
 import (
 	"context"
-	"flag"
+	"os"
 	"path/filepath"
+	"time"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
@@ -24,17 +25,21 @@ type KubeClient struct {
 	store     *store
 }
 
-func NewKubeClient() *KubeClient {
-	var kubeconfig *string
+// DefaultKubeconfig returns the first file in $KUBECONFIG, or ~/.kube/config
+// when it is not set.
+func DefaultKubeconfig() string {
+	if paths := filepath.SplitList(os.Getenv("KUBECONFIG")); len(paths) > 0 {
+		return paths[0]
+	}
 	if home := homedir.HomeDir(); home != "" {
-		kubeconfig = flag.String("kubeconfig", filepath.Join(home, ".kube", "config"), "(optional) absolute path to the kubeconfig file")
-	} else {
-		kubeconfig = flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
+		return filepath.Join(home, ".kube", "config")
 	}
-	flag.Parse()
+	return ""
+}
 
+func NewKubeClient(kubeconfig string) *KubeClient {
 	// use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -51,7 +56,6 @@ func NewKubeClient() *KubeClient {
 
 	store := NewStore()
 	worker := NewWorker(clientset, metricsClientset, store)
-	worker.Run(context.Background())
 
 	return &KubeClient{
 		clientset: clientset,
@@ -61,6 +65,17 @@ func NewKubeClient() *KubeClient {
 	}
 }
 
+// Run keeps the store up to date by watching the cluster until ctx is done.
+func (k *KubeClient) Run(ctx context.Context) {
+	k.worker.Run(ctx)
+}
+
+// Load fills the store once from a list of the cluster, for one-shot commands
+// that do not watch.
+func (k *KubeClient) Load(ctx context.Context) error {
+	return k.worker.Load(ctx)
+}
+
 func (k *KubeClient) GetNodes(ctx context.Context) ([]Node, error) {
 	return k.store.GetNodes()
 }
@@ -76,3 +91,42 @@ func (k *KubeClient) GetPods(ctx context.Context, node string) ([]Pod, error) {
 func (k *KubeClient) GetNode(ctx context.Context, name string) (Node, error) {
 	return k.store.GetNode(name)
 }
+
+func (k *KubeClient) GetDisruptionBudgets(ctx context.Context) ([]DisruptionBudget, error) {
+	return k.store.GetDisruptionBudgets()
+}
+
+func (k *KubeClient) GetEvents(ctx context.Context) ([]Event, error) {
+	return k.store.GetEvents()
+}
+
+func (k *KubeClient) GetClaims(ctx context.Context) ([]Claim, error) {
+	return k.store.GetClaims()
+}
+
+func (k *KubeClient) GetVolumes(ctx context.Context) ([]Volume, error) {
+	return k.store.GetVolumes()
+}
+
+func (k *KubeClient) GetStorageClasses(ctx context.Context) ([]StorageClass, error) {
+	return k.store.GetStorageClasses()
+}
+
+func (k *KubeClient) GetLargestNodeStorage(ctx context.Context) (int64, error) {
+	return k.store.GetLargestNodeStorage()
+}
+
+// KeepHistory sets how long usage samples are kept, how often they are taken
+// and how many pod samples are kept at most, 24 hours every minute and 500000
+// pod samples by default.
+func (k *KubeClient) KeepHistory(retention, interval time.Duration, maxPodSamples int) {
+	k.store.KeepHistory(retention, interval, maxPodSamples)
+}
+
+func (k *KubeClient) GetHistory(ctx context.Context, since time.Time) ([]Sample, error) {
+	return k.store.GetHistory(since)
+}
+
+func (k *KubeClient) Clientset() kubernetes.Interface {
+	return k.clientset
+}
@@ -1,5 +1,12 @@
 package kubeclient
 
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type (
 	Node struct {
 		Name              string
@@ -7,6 +14,22 @@ type (
 		AllocatableMemory int64
 		TotalMemory       int64
 		AvailableCPU      int64
+		TotalCPU          int64
+		AllocatablePods   int64
+		TotalPods         int64
+		// AllocatableEphemeralStorage and TotalEphemeralStorage are the
+		// local storage for container writable layers, logs and emptyDirs.
+		AllocatableEphemeralStorage int64
+		TotalEphemeralStorage       int64
+		InstanceType                string
+		Labels                      map[string]string
+		Unschedulable               bool
+		Taints                      []corev1.Taint
+		Created                     time.Time
+		// ExtendedCapacity and ExtendedAllocatable are the extended resources
+		// of the node, like nvidia.com/gpu, by resource name.
+		ExtendedCapacity    map[string]int64
+		ExtendedAllocatable map[string]int64
 	}
 
 	Pod struct {
@@ -15,6 +38,178 @@ type (
 		Namespace   string
 		MemoryUsage int64
 		CPUUsage    int64
-		Status 	string
+		// Status is the status `kubectl get pods` shows, Ready its ready
+		// containers like 1/2.
+		Status string
+		Ready  string
+		Phase  string
+		// Workload is the controller running the pod as Kind/Name, with
+		// ReplicaSets resolved to their Deployment.
+		Workload                string
+		CPURequest              int64
+		CPULimit                int64
+		MemoryRequest           int64
+		MemoryLimit             int64
+		EphemeralStorageRequest int64
+		EphemeralStorageLimit   int64
+		Labels                  map[string]string
+		Annotations             map[string]string
+		Containers              []Container
+		QOSClass                string
+		Priority                int32
+		NodeSelector            map[string]string
+		Tolerations             []corev1.Toleration
+		Affinity                *corev1.Affinity
+		// Mirror pods are the API server's view of static pods run by the
+		// kubelet, which cannot be evicted.
+		Mirror bool
+		// ScheduledReason and ScheduledMessage come from the PodScheduled
+		// condition while the pod is not scheduled.
+		ScheduledReason  string
+		ScheduledMessage string
+		// SchedulingFailure is the message of the latest FailedScheduling
+		// event of the pod.
+		SchedulingFailure string
+		// Restarts is the sum of the restarts of the containers.
+		Restarts int32
+		// Claims are the PersistentVolumeClaims the pod mounts. The store
+		// fills StorageRequest and StorageUsage from them when the pods are
+		// read.
+		Claims         []string
+		StorageRequest int64
+		StorageUsage   int64
+		// ExtendedRequests and ExtendedLimits are the effective requests and
+		// limits of the pod for extended resources, by resource name.
+		ExtendedRequests map[string]int64
+		ExtendedLimits   map[string]int64
+	}
+
+	DisruptionBudget struct {
+		Name               string
+		Namespace          string
+		Selector           *v1.LabelSelector
+		DisruptionsAllowed int32
+	}
+
+	// Event is a Kubernetes event from either the core/v1 or the
+	// events.k8s.io API, which serve the same objects.
+	Event struct {
+		UID string
+		// Type is Normal or Warning.
+		Type    string
+		Reason  string
+		Message string
+		Object  ObjectReference
+		Source  string
+		Count   int32
+		First   time.Time
+		Last    time.Time
+	}
+
+	ObjectReference struct {
+		Kind      string
+		Namespace string
+		Name      string
+	}
+
+	// Claim is a PersistentVolumeClaim. Used and Available come from the
+	// volume stats of the kubelet running a pod that mounts it, HasStats
+	// tells if there were any.
+	Claim struct {
+		Name         string
+		Namespace    string
+		Phase        string
+		StorageClass string
+		Volume       string
+		AccessModes  []string
+		Request      int64
+		Capacity     int64
+		Created      time.Time
+		Used         int64
+		Available    int64
+		HasStats     bool
+	}
+
+	// Volume is a PersistentVolume with the claim bound to it, if any.
+	Volume struct {
+		Name           string
+		Phase          string
+		StorageClass   string
+		Capacity       int64
+		ReclaimPolicy  string
+		ClaimNamespace string
+		ClaimName      string
+	}
+
+	StorageClass struct {
+		Name              string
+		Provisioner       string
+		ReclaimPolicy     string
+		VolumeBindingMode string
+		AllowExpansion    bool
+		Default           bool
+	}
+
+	// VolumeStats is the usage of a claim's volume reported by a kubelet.
+	VolumeStats struct {
+		Used      int64
+		Capacity  int64
+		Available int64
+	}
+
+	Container struct {
+		Name          string
+		CPURequest    int64
+		CPULimit      int64
+		MemoryRequest int64
+		MemoryLimit   int64
+		CPUUsage      int64
+		MemoryUsage   int64
+		Restarts      int32
+		// State is Waiting, Running or Terminated, with the reason of the
+		// waiting or terminated state, like CrashLoopBackOff.
+		State       string
+		StateReason string
+		// The last termination is the current one for terminated containers.
+		LastTerminationReason string
+		LastExitCode          int32
+		LastTerminated        time.Time
+		Image                 string
+	}
+
+	// Sample is the state of the cluster at one point of the usage history.
+	Sample struct {
+		Time  time.Time
+		Nodes []NodeSample
+		Pods  []PodSample
+	}
+
+	NodeSample struct {
+		Name              string
+		Labels            map[string]string
+		TotalCPU          int64
+		TotalMemory       int64
+		AvailableCPU      int64
+		AllocatableMemory int64
+	}
+
+	PodSample struct {
+		Name          string
+		Namespace     string
+		Node          string
+		Workload      string
+		Labels        map[string]string
+		CPUUsage      int64
+		MemoryUsage   int64
+		CPURequest    int64
+		MemoryRequest int64
+		Restarts      int32
+		Containers    []ContainerSample
+	}
+
+	ContainerSample struct {
+		Name        string
+		CPUUsage    int64
+		MemoryUsage int64
 	}
 )
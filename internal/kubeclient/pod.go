@@ -0,0 +1,242 @@
+package kubeclient
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func toPod(p *corev1.Pod) Pod {
+	requests, limits := podResources(p)
+	status, ready := podStatus(p)
+	containers := containers(p)
+	var restarts int32
+	for _, c := range containers {
+		restarts += c.Restarts
+	}
+	var scheduledReason, scheduledMessage string
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status != corev1.ConditionTrue {
+			scheduledReason, scheduledMessage = condition.Reason, condition.Message
+		}
+	}
+	return Pod{
+		Name:                    p.Name,
+		Node:                    p.Spec.NodeName,
+		Namespace:               p.Namespace,
+		Status:                  status,
+		Ready:                   ready,
+		Phase:                   string(p.Status.Phase),
+		Workload:                workload(p),
+		CPURequest:              requests.Cpu().MilliValue(),
+		CPULimit:                limits.Cpu().MilliValue(),
+		MemoryRequest:           requests.Memory().Value(),
+		MemoryLimit:             limits.Memory().Value(),
+		EphemeralStorageRequest: requests.StorageEphemeral().Value(),
+		EphemeralStorageLimit:   limits.StorageEphemeral().Value(),
+		Labels:                  p.Labels,
+		Annotations:             groupableAnnotations(p.Annotations),
+		Containers:              containers,
+		QOSClass:                qosClass(p),
+		Priority:                priority(p),
+		NodeSelector:            p.Spec.NodeSelector,
+		Tolerations:             p.Spec.Tolerations,
+		Affinity:                p.Spec.Affinity,
+		Mirror:                  p.Annotations[corev1.MirrorPodAnnotationKey] != "",
+		ScheduledReason:         scheduledReason,
+		ScheduledMessage:        scheduledMessage,
+		Restarts:                restarts,
+		Claims:                  claimNames(p),
+		ExtendedRequests:        extendedResources(requests),
+		ExtendedLimits:          extendedResources(limits),
+	}
+}
+
+func priority(p *corev1.Pod) int32 {
+	if p.Spec.Priority == nil {
+		return 0
+	}
+	return *p.Spec.Priority
+}
+
+// qosClass returns the QoS class the API server assigned to the pod, working
+// it out from the containers' resources when it is not set yet.
+func qosClass(p *corev1.Pod) string {
+	if p.Status.QOSClass != "" {
+		return string(p.Status.QOSClass)
+	}
+	all := make([]corev1.Container, 0, len(p.Spec.InitContainers)+len(p.Spec.Containers))
+	all = append(append(all, p.Spec.InitContainers...), p.Spec.Containers...)
+	bestEffort, guaranteed := true, true
+	for _, c := range all {
+		if len(c.Resources.Requests) > 0 || len(c.Resources.Limits) > 0 {
+			bestEffort = false
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			limit, ok := c.Resources.Limits[name]
+			if !ok {
+				guaranteed = false
+				continue
+			}
+			if request, ok := c.Resources.Requests[name]; ok && request.Cmp(limit) != 0 {
+				guaranteed = false
+			}
+		}
+	}
+	switch {
+	case bestEffort:
+		return string(corev1.PodQOSBestEffort)
+	case guaranteed:
+		return string(corev1.PodQOSGuaranteed)
+	default:
+		return string(corev1.PodQOSBurstable)
+	}
+}
+
+func containers(p *corev1.Pod) []Container {
+	result := make([]Container, 0, len(p.Spec.Containers))
+	for _, c := range p.Spec.Containers {
+		container := Container{
+			Name:          c.Name,
+			CPURequest:    c.Resources.Requests.Cpu().MilliValue(),
+			CPULimit:      c.Resources.Limits.Cpu().MilliValue(),
+			MemoryRequest: c.Resources.Requests.Memory().Value(),
+			MemoryLimit:   c.Resources.Limits.Memory().Value(),
+			Image:         c.Image,
+		}
+		for _, status := range p.Status.ContainerStatuses {
+			if status.Name == c.Name {
+				setContainerStatus(&container, status)
+			}
+		}
+		result = append(result, container)
+	}
+	return result
+}
+
+func setContainerStatus(c *Container, status corev1.ContainerStatus) {
+	c.Restarts = status.RestartCount
+	switch {
+	case status.State.Waiting != nil:
+		c.State, c.StateReason = "Waiting", status.State.Waiting.Reason
+	case status.State.Running != nil:
+		c.State = "Running"
+	case status.State.Terminated != nil:
+		c.State, c.StateReason = "Terminated", status.State.Terminated.Reason
+	}
+	terminated := status.State.Terminated
+	if terminated == nil {
+		terminated = status.LastTerminationState.Terminated
+	}
+	if terminated != nil {
+		c.LastTerminationReason = terminated.Reason
+		c.LastExitCode = terminated.ExitCode
+		c.LastTerminated = terminated.FinishedAt.Time
+	}
+}
+
+// podResources returns the effective requests and limits of a pod the way the
+// scheduler computes them: the sum over the containers, or the largest init
+// container when that is higher, plus the pod overhead. A resource is only
+// limited when every container limits it.
+func podResources(p *corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	unlimited := map[corev1.ResourceName]bool{}
+	for _, c := range p.Spec.Containers {
+		addResources(requests, c.Resources.Requests)
+		addResources(limits, c.Resources.Limits)
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+			if _, ok := c.Resources.Limits[name]; !ok {
+				unlimited[name] = true
+			}
+		}
+	}
+	for _, c := range p.Spec.InitContainers {
+		maxResources(requests, c.Resources.Requests)
+		maxResources(limits, c.Resources.Limits)
+	}
+	addResources(requests, p.Spec.Overhead)
+	addResources(limits, p.Spec.Overhead)
+	for name := range unlimited {
+		delete(limits, name)
+	}
+	return requests, limits
+}
+
+func addResources(total corev1.ResourceList, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+func maxResources(total corev1.ResourceList, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// extendedResources returns the extended resources of the list, the ones
+// with a domain outside kubernetes.io, by name. Extended resources are whole
+// numbers.
+func extendedResources(resources corev1.ResourceList) map[string]int64 {
+	var result map[string]int64
+	for name, quantity := range resources {
+		if !isExtendedResource(name) {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]int64)
+		}
+		result[string(name)] = quantity.Value()
+	}
+	return result
+}
+
+func isExtendedResource(name corev1.ResourceName) bool {
+	n := string(name)
+	return strings.Contains(n, "/") &&
+		!strings.Contains(n, corev1.ResourceDefaultNamespacePrefix) &&
+		!strings.HasPrefix(n, corev1.DefaultResourceRequestsPrefix)
+}
+
+// maxAnnotationValue is the longest annotation value kept, the limit of label
+// values. Longer ones, like last-applied configurations, are documents rather
+// than values to group by and are not worth their memory.
+const maxAnnotationValue = 63
+
+// groupableAnnotations keeps the annotations short enough to group pods by.
+func groupableAnnotations(annotations map[string]string) map[string]string {
+	var result map[string]string
+	for k, v := range annotations {
+		if v == "" || len(v) > maxAnnotationValue {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// workload names the controller of a pod as Kind/Name. Pods of a ReplicaSet
+// created by a Deployment are attributed to the Deployment.
+func workload(p *corev1.Pod) string {
+	owner := v1.GetControllerOf(p)
+	if owner == nil {
+		return ""
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash := p.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment/" + strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return owner.Kind + "/" + owner.Name
+}
@@ -0,0 +1,114 @@
+package kubeclient
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// nodeLostReason is the pod status reason the node lifecycle controller sets
+// on pods of unreachable nodes.
+const nodeLostReason = "NodeLost"
+
+// podStatus derives the STATUS and READY columns of `kubectl get pods`: the
+// reason of the first failing init container or of the last waiting or
+// terminated container, falling back to the phase, and the ready containers
+// out of the containers, counting sidecar init containers.
+func podStatus(p *corev1.Pod) (string, string) {
+	total := len(p.Spec.Containers)
+	ready := 0
+	reason := string(p.Status.Phase)
+	if p.Status.Reason != "" {
+		reason = p.Status.Reason
+	}
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Reason == corev1.PodReasonSchedulingGated {
+			reason = corev1.PodReasonSchedulingGated
+		}
+	}
+
+	sidecars := make(map[string]bool)
+	for _, c := range p.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			sidecars[c.Name] = true
+			total++
+		}
+	}
+
+	initializing := false
+	for i, c := range p.Status.InitContainerStatuses {
+		switch {
+		case c.State.Terminated != nil && c.State.Terminated.ExitCode == 0:
+			continue
+		// Started sidecars run next to the containers; the ones that have
+		// not started hold back the pod like other init containers.
+		case sidecars[c.Name] && c.Started != nil && *c.Started:
+			if c.Ready {
+				ready++
+			}
+			continue
+		case c.State.Terminated != nil:
+			reason = "Init:" + terminatedReason(c.State.Terminated)
+		case c.State.Waiting != nil && c.State.Waiting.Reason != "" && c.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + c.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(p.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing || podCondition(p, corev1.PodInitialized) {
+		running := false
+		for i := len(p.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			c := p.Status.ContainerStatuses[i]
+			switch {
+			case c.State.Waiting != nil && c.State.Waiting.Reason != "":
+				reason = c.State.Waiting.Reason
+			case c.State.Terminated != nil:
+				reason = terminatedReason(c.State.Terminated)
+			case c.Ready && c.State.Running != nil:
+				running = true
+				ready++
+			}
+		}
+		// A pod with a completed container is running while another one
+		// still runs.
+		if reason == "Completed" && running {
+			if podCondition(p, corev1.PodReady) {
+				reason = "Running"
+			} else {
+				reason = "NotReady"
+			}
+		}
+	}
+
+	if p.DeletionTimestamp != nil {
+		if p.Status.Reason == nodeLostReason {
+			reason = "Unknown"
+		} else {
+			reason = "Terminating"
+		}
+	}
+	return reason, fmt.Sprintf("%d/%d", ready, total)
+}
+
+func terminatedReason(t *corev1.ContainerStateTerminated) string {
+	switch {
+	case t.Reason != "":
+		return t.Reason
+	case t.Signal != 0:
+		return fmt.Sprintf("Signal:%d", t.Signal)
+	default:
+		return fmt.Sprintf("ExitCode:%d", t.ExitCode)
+	}
+}
+
+func podCondition(p *corev1.Pod, condition corev1.PodConditionType) bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == condition && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package kubeclient_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_PodStatus(t *testing.T) {
+	tests := []struct {
+		fixture string
+		status  string
+		ready   string
+	}{
+		{"running.yaml", "Running", "1/1"},
+		{"crash-loop-back-off.yaml", "CrashLoopBackOff", "0/1"},
+		{"image-pull-back-off.yaml", "ImagePullBackOff", "0/1"},
+		{"terminating.yaml", "Terminating", "1/1"},
+		{"node-lost.yaml", "Unknown", "1/1"},
+		{"init-running.yaml", "Init:0/2", "0/1"},
+		{"init-error.yaml", "Init:Error", "0/1"},
+		{"init-crash-loop-back-off.yaml", "Init:CrashLoopBackOff", "0/1"},
+		{"completed.yaml", "Completed", "0/1"},
+		{"oom-killed.yaml", "OOMKilled", "0/1"},
+		{"signal.yaml", "Signal:15", "0/1"},
+		{"evicted.yaml", "Evicted", "0/1"},
+		{"partially-ready.yaml", "Running", "1/2"},
+		{"completed-sidecar-running.yaml", "NotReady", "1/2"},
+		{"sidecar.yaml", "Running", "2/2"},
+		{"sidecar-image-pull-back-off.yaml", "Init:ImagePullBackOff", "0/2"},
+		{"scheduling-gated.yaml", "SchedulingGated", "0/1"},
+		{"unschedulable.yaml", "Pending", "0/1"},
+	}
+	for _, tt := range tests {
+		t.Run("given "+tt.fixture+", then the status is "+tt.status, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "pods", tt.fixture))
+			assert.Nil(t, err)
+			var pod corev1.Pod
+			assert.Nil(t, yaml.Unmarshal(data, &pod))
+
+			store := kubeclient.NewStore()
+			store.AddPod(&pod)
+			pods, err := store.GetPods("")
+			assert.Nil(t, err)
+			assert.Equal(t, tt.status, pods[0].Status)
+			assert.Equal(t, tt.ready, pods[0].Ready)
+			assert.Equal(t, string(pod.Status.Phase), pods[0].Phase)
+		})
+	}
+}
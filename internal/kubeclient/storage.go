@@ -0,0 +1,136 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// defaultStorageClassAnnotation marks the storage class claims without one
+// get.
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+func toClaim(c *corev1.PersistentVolumeClaim) Claim {
+	claim := Claim{
+		Name:      c.Name,
+		Namespace: c.Namespace,
+		Phase:     string(c.Status.Phase),
+		Volume:    c.Spec.VolumeName,
+		Request:   c.Spec.Resources.Requests.Storage().Value(),
+		Capacity:  c.Status.Capacity.Storage().Value(),
+		Created:   c.CreationTimestamp.Time,
+	}
+	if c.Spec.StorageClassName != nil {
+		claim.StorageClass = *c.Spec.StorageClassName
+	}
+	for _, mode := range c.Spec.AccessModes {
+		claim.AccessModes = append(claim.AccessModes, string(mode))
+	}
+	return claim
+}
+
+func toVolume(v *corev1.PersistentVolume) Volume {
+	volume := Volume{
+		Name:          v.Name,
+		Phase:         string(v.Status.Phase),
+		StorageClass:  v.Spec.StorageClassName,
+		Capacity:      v.Spec.Capacity.Storage().Value(),
+		ReclaimPolicy: string(v.Spec.PersistentVolumeReclaimPolicy),
+	}
+	if v.Spec.ClaimRef != nil {
+		volume.ClaimNamespace = v.Spec.ClaimRef.Namespace
+		volume.ClaimName = v.Spec.ClaimRef.Name
+	}
+	return volume
+}
+
+func toStorageClass(c *storagev1.StorageClass) StorageClass {
+	class := StorageClass{
+		Name:        c.Name,
+		Provisioner: c.Provisioner,
+		Default:     c.Annotations[defaultStorageClassAnnotation] == "true",
+	}
+	if c.ReclaimPolicy != nil {
+		class.ReclaimPolicy = string(*c.ReclaimPolicy)
+	}
+	if c.VolumeBindingMode != nil {
+		class.VolumeBindingMode = string(*c.VolumeBindingMode)
+	}
+	if c.AllowVolumeExpansion != nil {
+		class.AllowExpansion = *c.AllowVolumeExpansion
+	}
+	return class
+}
+
+// claimNames returns the PersistentVolumeClaims the pod mounts.
+func claimNames(p *corev1.Pod) []string {
+	var names []string
+	for _, v := range p.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			names = append(names, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return names
+}
+
+// summary is the part of the kubelet stats summary with the volume stats of
+// the pods.
+type summary struct {
+	Pods []struct {
+		Volumes []struct {
+			UsedBytes      *int64 `json:"usedBytes"`
+			CapacityBytes  *int64 `json:"capacityBytes"`
+			AvailableBytes *int64 `json:"availableBytes"`
+			PVCRef         *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// ParseVolumeStats reads the usage of the claims' volumes from a kubelet
+// stats summary, from /api/v1/nodes/<node>/proxy/stats/summary.
+func ParseVolumeStats(data []byte) (map[ObjectReference]VolumeStats, error) {
+	var s summary
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	stats := make(map[ObjectReference]VolumeStats)
+	for _, p := range s.Pods {
+		for _, v := range p.Volumes {
+			if v.PVCRef == nil || v.UsedBytes == nil {
+				continue
+			}
+			volume := VolumeStats{Used: *v.UsedBytes}
+			if v.CapacityBytes != nil {
+				volume.Capacity = *v.CapacityBytes
+			}
+			if v.AvailableBytes != nil {
+				volume.Available = *v.AvailableBytes
+			}
+			stats[claimKey(v.PVCRef.Namespace, v.PVCRef.Name)] = volume
+		}
+	}
+	return stats, nil
+}
+
+func sortedClaims(claims map[ObjectReference]Claim) []Claim {
+	result := make([]Claim, 0, len(claims))
+	for _, c := range claims {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func claimKey(namespace, name string) ObjectReference {
+	return ObjectReference{Kind: "PersistentVolumeClaim", Namespace: namespace, Name: name}
+}
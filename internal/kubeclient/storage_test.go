@@ -0,0 +1,144 @@
+package kubeclient_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Storage(t *testing.T) {
+	standard := "standard"
+	claim := func(name, request string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "shop"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &standard,
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(request)},
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+
+	t.Run("Volume stats are read from the kubelet summary", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/stats/summary.json")
+		assert.Nil(t, err)
+		stats, err := kubeclient.ParseVolumeStats(data)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(stats))
+		dataDB := stats[kubeclient.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "data-db-0"}]
+		assert.Equal(t, int64(3<<30), dataDB.Used)
+		assert.Equal(t, int64(10<<30), dataDB.Capacity)
+		assert.Equal(t, int64(7<<30), dataDB.Available)
+	})
+
+	t.Run("Claims are set, updated with stats and deleted", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		store.SetClaim(claim("data-db-0", "10Gi", corev1.ClaimBound))
+		store.SetClaim(claim("uploads", "5Gi", corev1.ClaimPending))
+		store.UpdateVolumeStats(map[kubeclient.ObjectReference]kubeclient.VolumeStats{
+			{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "data-db-0"}: {Used: 3 << 30, Available: 7 << 30},
+		})
+
+		claims, err := store.GetClaims()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(claims))
+		assert.Equal(t, "data-db-0", claims[0].Name)
+		assert.Equal(t, "standard", claims[0].StorageClass)
+		assert.Equal(t, []string{"ReadWriteOnce"}, claims[0].AccessModes)
+		assert.Equal(t, int64(10<<30), claims[0].Request)
+		assert.True(t, claims[0].HasStats)
+		assert.Equal(t, int64(3<<30), claims[0].Used)
+		assert.Equal(t, "Pending", claims[1].Phase)
+		assert.False(t, claims[1].HasStats)
+
+		store.DeleteClaim("shop", "uploads")
+		claims, _ = store.GetClaims()
+		assert.Equal(t, 1, len(claims))
+
+		store.SetError("volumeStats", errors.New("forbidden"))
+		claims, err = store.GetClaims()
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, len(claims))
+	})
+
+	t.Run("Pods and nodes sum the requests and usage of the claims they mount", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		store.AddPod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "db-0", Namespace: "shop"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Volumes: []corev1.Volume{
+					{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"}}},
+					{Name: "logs", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "logs-db-0"}}},
+					{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				},
+			},
+		})
+		store.SetClaim(claim("data-db-0", "10Gi", corev1.ClaimBound))
+		store.SetClaim(claim("logs-db-0", "1Gi", corev1.ClaimBound))
+		store.UpdateVolumeStats(map[kubeclient.ObjectReference]kubeclient.VolumeStats{
+			{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "data-db-0"}: {Used: 2 << 30},
+		})
+
+		pods, err := store.GetPods("node-1")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"data-db-0", "logs-db-0"}, pods[0].Claims)
+		assert.Equal(t, int64(11<<30), pods[0].StorageRequest)
+		assert.Equal(t, int64(2<<30), pods[0].StorageUsage)
+
+		largest, err := store.GetLargestNodeStorage()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(11<<30), largest)
+	})
+
+	t.Run("Volumes and storage classes are set and deleted", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		retain := corev1.PersistentVolumeReclaimRetain
+		store.SetVolume(&corev1.PersistentVolume{
+			ObjectMeta: v1.ObjectMeta{Name: "pv-1"},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName:              standard,
+				Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+				PersistentVolumeReclaimPolicy: retain,
+				ClaimRef:                      &corev1.ObjectReference{Namespace: "shop", Name: "data-db-0"},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+		})
+		expand := true
+		store.SetStorageClass(&storagev1.StorageClass{
+			ObjectMeta:           v1.ObjectMeta{Name: standard, Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}},
+			Provisioner:          "ebs.csi.aws.com",
+			AllowVolumeExpansion: &expand,
+		})
+
+		volumes, err := store.GetVolumes()
+		assert.Nil(t, err)
+		assert.Equal(t, "Released", volumes[0].Phase)
+		assert.Equal(t, int64(20<<30), volumes[0].Capacity)
+		assert.Equal(t, "Retain", volumes[0].ReclaimPolicy)
+		assert.Equal(t, "data-db-0", volumes[0].ClaimName)
+
+		classes, err := store.GetStorageClasses()
+		assert.Nil(t, err)
+		assert.True(t, classes[0].Default)
+		assert.True(t, classes[0].AllowExpansion)
+		assert.Equal(t, "ebs.csi.aws.com", classes[0].Provisioner)
+
+		store.DeleteVolume("pv-1")
+		store.DeleteStorageClass(standard)
+		volumes, _ = store.GetVolumes()
+		classes, _ = store.GetStorageClasses()
+		assert.Empty(t, volumes)
+		assert.Empty(t, classes)
+	})
+}
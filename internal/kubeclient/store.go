@@ -1,11 +1,15 @@
 package kubeclient
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	v1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
@@ -13,9 +17,20 @@ type store struct {
 	namespaces       []string
 	nodes            []Node
 	pods             []Pod
+	budgets          []DisruptionBudget
 	podsLastModified int64
 	errors           map[string]error
-	lock             sync.RWMutex
+	history          history
+	events           events
+	claims           map[ObjectReference]Claim
+	volumeStats      map[ObjectReference]VolumeStats
+	// largestNodeStorage is the most storage claimed by the pods of a node,
+	// computed on every volume stats refresh.
+	largestNodeStorage int64
+	volumes            map[string]Volume
+	storageClasses     map[string]StorageClass
+	now                func() time.Time
+	lock               sync.RWMutex
 }
 
 func NewStore() *store {
@@ -23,8 +38,23 @@ func NewStore() *store {
 		namespaces: make([]string, 0),
 		nodes:      make([]Node, 0),
 		pods:       make([]Pod, 0),
+		budgets:    make([]DisruptionBudget, 0),
 		errors:     make(map[string]error),
-		lock:       sync.RWMutex{},
+		history: history{
+			retention:     defaultHistoryRetention,
+			interval:      defaultHistoryInterval,
+			maxPodSamples: defaultHistoryPodSamples,
+		},
+		events: events{
+			perObject: defaultEventsPerObject,
+			retention: defaultEventRetention,
+		},
+		claims:         make(map[ObjectReference]Claim),
+		volumeStats:    make(map[ObjectReference]VolumeStats),
+		volumes:        make(map[string]Volume),
+		storageClasses: make(map[string]StorageClass),
+		now:            time.Now,
+		lock:           sync.RWMutex{},
 	}
 }
 
@@ -36,10 +66,16 @@ func (s *store) SetError(key string, err error) {
 }
 
 func (s *store) AddNamespace(namespace string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	s.namespaces = append(s.namespaces, namespace)
 }
 
 func (s *store) DeleteNamespace(namespace string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	for i, ns := range s.namespaces {
 		if ns == namespace {
 			s.namespaces = append(s.namespaces[:i], s.namespaces[i+1:]...)
@@ -55,21 +91,60 @@ func (s *store) GetNamespaces() ([]string, error) {
 	if err, found := s.errors["ns"]; found {
 		return nil, err
 	}
-	return s.namespaces, nil
+	return append([]string(nil), s.namespaces...), nil
 }
 
 func (s *store) AddNode(n *corev1.Node) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.nodes = append(s.nodes, toNode(n))
+}
+
+func (s *store) ModifyNode(n *corev1.Node) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, node := range s.nodes {
+		if node.Name == n.Name {
+			s.nodes[i] = toNode(n)
+			return
+		}
+	}
+	s.nodes = append(s.nodes, toNode(n))
+}
+
+func toNode(n *corev1.Node) Node {
 	var status string
 	if len(n.Status.Conditions) > 0 {
 		status = string(n.Status.Conditions[0].Type)
 	}
-	s.nodes = append(s.nodes, Node{
-		Name:              n.Name,
-		Status:            status,
-		AllocatableMemory: n.Status.Allocatable.Memory().Value(),
-		TotalMemory:       n.Status.Capacity.Memory().Value(),
-		AvailableCPU:      n.Status.Allocatable.Cpu().MilliValue(),
-	})
+	return Node{
+		Name:                        n.Name,
+		Status:                      status,
+		AllocatableMemory:           n.Status.Allocatable.Memory().Value(),
+		TotalMemory:                 n.Status.Capacity.Memory().Value(),
+		AvailableCPU:                n.Status.Allocatable.Cpu().MilliValue(),
+		TotalCPU:                    n.Status.Capacity.Cpu().MilliValue(),
+		AllocatablePods:             n.Status.Allocatable.Pods().Value(),
+		TotalPods:                   n.Status.Capacity.Pods().Value(),
+		AllocatableEphemeralStorage: n.Status.Allocatable.StorageEphemeral().Value(),
+		TotalEphemeralStorage:       n.Status.Capacity.StorageEphemeral().Value(),
+		InstanceType:                instanceType(n.Labels),
+		Labels:                      n.Labels,
+		Unschedulable:               n.Spec.Unschedulable,
+		Taints:                      n.Spec.Taints,
+		Created:                     n.CreationTimestamp.Time,
+		ExtendedCapacity:            extendedResources(n.Status.Capacity),
+		ExtendedAllocatable:         extendedResources(n.Status.Allocatable),
+	}
+}
+
+func instanceType(labels map[string]string) string {
+	if t, ok := labels[corev1.LabelInstanceTypeStable]; ok {
+		return t
+	}
+	return labels[corev1.LabelInstanceType]
 }
 
 func (s *store) GetNodes() ([]Node, error) {
@@ -79,10 +154,13 @@ func (s *store) GetNodes() ([]Node, error) {
 	if err, found := s.errors["nodes"]; found {
 		return nil, err
 	}
-	return s.nodes, nil
+	return append([]Node(nil), s.nodes...), nil
 }
 
 func (s *store) GetNode(name string) (Node, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	for _, node := range s.nodes {
 		if node.Name == name {
 			return node, nil
@@ -92,6 +170,9 @@ func (s *store) GetNode(name string) (Node, error) {
 }
 
 func (s *store) DeleteNode(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	for i, node := range s.nodes {
 		if node.Name == name {
 			s.nodes = append(s.nodes[:i], s.nodes[i+1:]...)
@@ -101,23 +182,40 @@ func (s *store) DeleteNode(name string) {
 }
 
 func (s *store) AddPod(p *corev1.Pod) {
-	s.pods = append(s.pods, Pod{
-		Name:      p.Name,
-		Node:      p.Spec.NodeName,
-		Namespace: p.Namespace,
-		Status:    string(p.Status.Phase),
-	})
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.pods = append(s.pods, toPod(p))
 	s.podsLastModified = time.Now().Unix()
 }
 
 func (s *store) ModifyPod(p *corev1.Pod) {
-	for i, pod := range s.pods {
-		if pod.Name == p.Name {
-			s.pods[i].Node = p.Spec.NodeName
-			s.pods[i].Status = string(p.Status.Phase)
-			break
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pod := toPod(p)
+	for i, existing := range s.pods {
+		if existing.Name == p.Name && existing.Namespace == p.Namespace {
+			// Usage comes from the metrics API, not from the pod.
+			pod.CPUUsage = existing.CPUUsage
+			pod.MemoryUsage = existing.MemoryUsage
+			if pod.Node == "" {
+				pod.SchedulingFailure = existing.SchedulingFailure
+			}
+			for j, c := range pod.Containers {
+				for _, e := range existing.Containers {
+					if e.Name == c.Name {
+						pod.Containers[j].CPUUsage = e.CPUUsage
+						pod.Containers[j].MemoryUsage = e.MemoryUsage
+					}
+				}
+			}
+			s.pods[i] = pod
+			s.podsLastModified = time.Now().Unix()
+			return
 		}
 	}
+	s.pods = append(s.pods, pod)
 	s.podsLastModified = time.Now().Unix()
 }
 
@@ -127,21 +225,42 @@ func (s *store) GetPods(node string) ([]Pod, error) {
 
 	err := s.errors["pods"]
 	if node == "" {
-		return s.pods, err
+		pods := make([]Pod, len(s.pods))
+		copy(pods, s.pods)
+		for i := range pods {
+			s.setStorage(&pods[i])
+		}
+		return pods, err
 	}
 
 	var result []Pod
 	for _, pod := range s.pods {
 		if pod.Node == node {
+			s.setStorage(&pod)
 			result = append(result, pod)
 		}
 	}
 	return result, err
 }
 
-func (s *store) DeletePod(name string) {
+// setStorage sums the requests and usage of the claims the pod mounts, which
+// change independently of the pod.
+func (s *store) setStorage(p *Pod) {
+	for _, name := range p.Claims {
+		key := claimKey(p.Namespace, name)
+		if claim, ok := s.claims[key]; ok {
+			p.StorageRequest += claim.Request
+		}
+		p.StorageUsage += s.volumeStats[key].Used
+	}
+}
+
+func (s *store) DeletePod(namespace, name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	for i, pod := range s.pods {
-		if pod.Name == name {
+		if pod.Name == name && pod.Namespace == namespace {
 			s.pods = append(s.pods[:i], s.pods[i+1:]...)
 			break
 		}
@@ -150,19 +269,261 @@ func (s *store) DeletePod(name string) {
 }
 
 func (s *store) UpdateMetrics(podMetrics []v1beta1.PodMetrics) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	metricsMap := make(map[string]v1beta1.PodMetrics)
 	for _, podMetrics := range podMetrics {
-		metricsMap[podMetrics.Name] = podMetrics
+		metricsMap[podMetrics.Namespace+"/"+podMetrics.Name] = podMetrics
 	}
 
 	for i, pod := range s.pods {
-		metrics, ok := metricsMap[pod.Name]
+		metrics, ok := metricsMap[pod.Namespace+"/"+pod.Name]
 		if ok {
-			cpu := metrics.Containers[0].Usage.Cpu()
-			memory := metrics.Containers[0].Usage.Memory()
+			// Readers share the containers of the pods they got, so they
+			// are replaced rather than updated in place.
+			containers := make([]Container, len(pod.Containers))
+			copy(containers, pod.Containers)
+			var cpu, memory int64
+			for _, container := range metrics.Containers {
+				cpu += container.Usage.Cpu().MilliValue()
+				memory += container.Usage.Memory().Value()
+				for j := range containers {
+					if containers[j].Name == container.Name {
+						containers[j].CPUUsage = container.Usage.Cpu().MilliValue()
+						containers[j].MemoryUsage = container.Usage.Memory().Value()
+					}
+				}
+			}
+
+			s.pods[i].CPUUsage = cpu
+			s.pods[i].MemoryUsage = memory
+			s.pods[i].Containers = containers
+		}
+	}
+	s.history.record(s.now(), s.nodes, s.pods)
+}
+
+// KeepHistory changes how long and how often usage samples are kept, and how
+// many pod samples at most. A zero retention disables the history, a zero
+// maxPodSamples leaves it uncapped.
+func (s *store) KeepHistory(retention, interval time.Duration, maxPodSamples int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.history.retention = retention
+	s.history.interval = interval
+	s.history.maxPodSamples = maxPodSamples
+	s.history.step = 0
+}
+
+// GetHistory returns the usage samples taken at or after since, oldest first.
+func (s *store) GetHistory(since time.Time) ([]Sample, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.history.since(since), nil
+}
+
+func (s *store) SetDisruptionBudget(pdb *policyv1.PodDisruptionBudget) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-			s.pods[i].CPUUsage = cpu.MilliValue()
-			s.pods[i].MemoryUsage = memory.Value()
+	budget := DisruptionBudget{
+		Name:               pdb.Name,
+		Namespace:          pdb.Namespace,
+		Selector:           pdb.Spec.Selector,
+		DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+	}
+	for i, existing := range s.budgets {
+		if existing.Name == pdb.Name && existing.Namespace == pdb.Namespace {
+			s.budgets[i] = budget
+			return
+		}
+	}
+	s.budgets = append(s.budgets, budget)
+}
+
+func (s *store) DeleteDisruptionBudget(namespace, name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, budget := range s.budgets {
+		if budget.Name == name && budget.Namespace == namespace {
+			s.budgets = append(s.budgets[:i], s.budgets[i+1:]...)
+			break
 		}
 	}
 }
+
+func (s *store) GetDisruptionBudgets() ([]DisruptionBudget, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return append([]DisruptionBudget(nil), s.budgets...), s.errors["pdbs"]
+}
+
+// AddEvent records the event in the history of the object it is about. The
+// latest FailedScheduling message of a pending pod is kept on the pod.
+func (s *store) AddEvent(event Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.events.add(event)
+	if event.Object.Kind != "Pod" || event.Reason != "FailedScheduling" {
+		return
+	}
+	for i, pod := range s.pods {
+		if pod.Name == event.Object.Name && pod.Namespace == event.Object.Namespace && pod.Node == "" {
+			s.pods[i].SchedulingFailure = event.Message
+			return
+		}
+	}
+}
+
+// PruneEvents drops the events of objects without an event for longer than
+// the event retention.
+func (s *store) PruneEvents() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.events.prune(s.now())
+}
+
+// GetEvents returns the kept events of all objects, newest first.
+func (s *store) GetEvents() ([]Event, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.events.all(), s.errors["events"]
+}
+
+func (s *store) SetClaim(c *corev1.PersistentVolumeClaim) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.claims[claimKey(c.Namespace, c.Name)] = toClaim(c)
+}
+
+func (s *store) DeleteClaim(namespace, name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.claims, claimKey(namespace, name))
+	delete(s.volumeStats, claimKey(namespace, name))
+}
+
+// GetClaims returns the claims with the latest stats of their volumes.
+func (s *store) GetClaims() ([]Claim, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	claims := sortedClaims(s.claims)
+	for i, c := range claims {
+		if stats, ok := s.volumeStats[claimKey(c.Namespace, c.Name)]; ok {
+			claims[i].Used = stats.Used
+			claims[i].Available = stats.Available
+			claims[i].HasStats = true
+		}
+	}
+	return claims, errors.Join(s.errors["pvcs"], s.errors["volumeStats"])
+}
+
+// UpdateVolumeStats replaces the volume stats of the claims with the ones
+// read from the kubelets, and sums the storage claimed per node again.
+func (s *store) UpdateVolumeStats(stats map[ObjectReference]VolumeStats) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.volumeStats = stats
+	nodes := make(map[string]int64)
+	s.largestNodeStorage = 0
+	for _, p := range s.pods {
+		if p.Node == "" || p.Phase == string(corev1.PodSucceeded) || p.Phase == string(corev1.PodFailed) {
+			continue
+		}
+		for _, name := range p.Claims {
+			nodes[p.Node] += s.claims[claimKey(p.Namespace, name)].Request
+		}
+		s.largestNodeStorage = max(s.largestNodeStorage, nodes[p.Node])
+	}
+}
+
+// GetLargestNodeStorage returns the most storage claimed by the pods of a
+// node, as of the last volume stats refresh.
+func (s *store) GetLargestNodeStorage() (int64, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.largestNodeStorage, s.errors["pvcs"]
+}
+
+// claimNodes returns the nodes running pods with claims, whose kubelets have
+// volume stats to read.
+func (s *store) claimNodes() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	seen := make(map[string]bool)
+	var nodes []string
+	for _, p := range s.pods {
+		if p.Node == "" || len(p.Claims) == 0 || seen[p.Node] {
+			continue
+		}
+		seen[p.Node] = true
+		nodes = append(nodes, p.Node)
+	}
+	return nodes
+}
+
+func (s *store) SetVolume(v *corev1.PersistentVolume) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.volumes[v.Name] = toVolume(v)
+}
+
+func (s *store) DeleteVolume(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.volumes, name)
+}
+
+func (s *store) GetVolumes() ([]Volume, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	volumes := make([]Volume, 0, len(s.volumes))
+	for _, v := range s.volumes {
+		volumes = append(volumes, v)
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes, s.errors["pvs"]
+}
+
+func (s *store) SetStorageClass(c *storagev1.StorageClass) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.storageClasses[c.Name] = toStorageClass(c)
+}
+
+func (s *store) DeleteStorageClass(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.storageClasses, name)
+}
+
+func (s *store) GetStorageClasses() ([]StorageClass, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	classes := make([]StorageClass, 0, len(s.storageClasses))
+	for _, c := range s.storageClasses {
+		classes = append(classes, c)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes, s.errors["storageclasses"]
+}
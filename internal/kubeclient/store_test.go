@@ -2,13 +2,26 @@ package kubeclient_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jawahars16/hawk8s/internal/kubeclient"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
+func resources(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
 func Test_Store(t *testing.T) {
 	t.Run("Set error", func(t *testing.T) {
 		store := kubeclient.NewStore()
@@ -43,4 +56,369 @@ func Test_Store(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, 1, len(nodes))
 	})
+
+	t.Run("Add pod with requests, limits and workload", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		controller := true
+		store.AddPod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{
+				Name:            "web-7d9f8b6c5-x2x4z",
+				Namespace:       "team-a",
+				Labels:          map[string]string{"pod-template-hash": "7d9f8b6c5"},
+				Annotations:     map[string]string{"owner": "team-a", "kubectl.kubernetes.io/last-applied-configuration": strings.Repeat("{}", 64)},
+				OwnerReferences: []v1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d9f8b6c5", Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{Requests: resources("2", "64Mi")}},
+				},
+				Containers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{Requests: resources("250m", "128Mi"), Limits: resources("500m", "256Mi")}},
+					{Resources: corev1.ResourceRequirements{Requests: resources("250m", "128Mi")}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+		pods, err := store.GetPods("")
+		assert.Nil(t, err)
+		assert.Equal(t, "Deployment/web", pods[0].Workload)
+		assert.Equal(t, "Running", pods[0].Phase)
+		assert.Equal(t, int64(2000), pods[0].CPURequest, "the init container request is higher")
+		assert.Equal(t, int64(256<<20), pods[0].MemoryRequest)
+		assert.Equal(t, int64(0), pods[0].CPULimit, "one container is not limited")
+		assert.Equal(t, map[string]string{"owner": "team-a"}, pods[0].Annotations, "long annotations are dropped")
+	})
+
+	t.Run("Extended resources of nodes and pods are kept by name", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		capacity := resources("8", "32Gi")
+		capacity["nvidia.com/gpu"] = resource.MustParse("4")
+		capacity[corev1.ResourceEphemeralStorage] = resource.MustParse("100Gi")
+		capacity["hugepages-2Mi"] = resource.MustParse("0")
+		allocatable := resources("7", "30Gi")
+		allocatable["nvidia.com/gpu"] = resource.MustParse("3")
+		store.AddNode(&corev1.Node{
+			ObjectMeta: v1.ObjectMeta{Name: "gpu-1"},
+			Status:     corev1.NodeStatus{Capacity: capacity, Allocatable: allocatable},
+		})
+		gpu := func(n string) corev1.ResourceList {
+			list := resources("1", "1Gi")
+			list["nvidia.com/gpu"] = resource.MustParse(n)
+			return list
+		}
+		store.AddPod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "train", Namespace: "ml"},
+			Spec: corev1.PodSpec{
+				NodeName: "gpu-1",
+				Containers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{Requests: gpu("1"), Limits: gpu("1")}},
+					{Resources: corev1.ResourceRequirements{Requests: gpu("1"), Limits: gpu("1")}},
+					{Resources: corev1.ResourceRequirements{Requests: resources("100m", "64Mi")}},
+				},
+			},
+		})
+
+		nodes, err := store.GetNodes()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int64{"nvidia.com/gpu": 4}, nodes[0].ExtendedCapacity)
+		assert.Equal(t, map[string]int64{"nvidia.com/gpu": 3}, nodes[0].ExtendedAllocatable)
+
+		pods, err := store.GetPods("gpu-1")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int64{"nvidia.com/gpu": 2}, pods[0].ExtendedRequests)
+		assert.Equal(t, map[string]int64{"nvidia.com/gpu": 2}, pods[0].ExtendedLimits)
+	})
+
+	t.Run("Ephemeral storage and pod capacity of nodes and ephemeral storage of pods", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		capacity := resources("4", "16Gi")
+		capacity[corev1.ResourceEphemeralStorage] = resource.MustParse("100Gi")
+		capacity[corev1.ResourcePods] = resource.MustParse("110")
+		allocatable := resources("4", "15Gi")
+		allocatable[corev1.ResourceEphemeralStorage] = resource.MustParse("90Gi")
+		allocatable[corev1.ResourcePods] = resource.MustParse("100")
+		store.AddNode(&corev1.Node{
+			ObjectMeta: v1.ObjectMeta{Name: "node-1"},
+			Status:     corev1.NodeStatus{Capacity: capacity, Allocatable: allocatable},
+		})
+		ephemeral := func(size string) corev1.ResourceList {
+			return corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse(size)}
+		}
+		store.AddPod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "build", Namespace: "ci"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Containers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{Requests: ephemeral("2Gi"), Limits: ephemeral("4Gi")}},
+					{Resources: corev1.ResourceRequirements{Requests: ephemeral("1Gi")}},
+				},
+			},
+		})
+
+		nodes, err := store.GetNodes()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(100<<30), nodes[0].TotalEphemeralStorage)
+		assert.Equal(t, int64(90<<30), nodes[0].AllocatableEphemeralStorage)
+		assert.Equal(t, int64(110), nodes[0].TotalPods)
+		assert.Equal(t, int64(100), nodes[0].AllocatablePods)
+
+		pods, err := store.GetPods("node-1")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3<<30), pods[0].EphemeralStorageRequest)
+		// The second container is not limited, so neither is the pod.
+		assert.Equal(t, int64(0), pods[0].EphemeralStorageLimit)
+	})
+
+	t.Run("Pods with the same name in different namespaces", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "team-a"}})
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "team-b"}})
+		store.UpdateMetrics([]v1beta1.PodMetrics{{
+			ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "team-b"},
+			Containers: []v1beta1.ContainerMetrics{
+				{Usage: resources("100m", "10Mi")},
+				{Usage: resources("50m", "5Mi")},
+			},
+		}})
+		store.DeletePod("team-a", "app")
+
+		pods, err := store.GetPods("")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(pods))
+		assert.Equal(t, "team-b", pods[0].Namespace)
+		assert.Equal(t, int64(150), pods[0].CPUUsage)
+		assert.Equal(t, int64(15<<20), pods[0].MemoryUsage)
+	})
+
+	t.Run("Modify node updates its status and labels", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		store.AddNode(&corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-1"}})
+		store.ModifyNode(&corev1.Node{
+			ObjectMeta: v1.ObjectMeta{Name: "node-1", Labels: map[string]string{"spot": "true"}},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		})
+
+		nodes, err := store.GetNodes()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(nodes))
+		assert.Equal(t, "Ready", nodes[0].Status)
+		assert.Equal(t, "true", nodes[0].Labels["spot"])
+	})
+
+	t.Run("History keeps a sample per interval", func(t *testing.T) {
+		metrics := []v1beta1.PodMetrics{{
+			ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default"},
+			Containers: []v1beta1.ContainerMetrics{{Usage: resources("100m", "10Mi")}},
+		}}
+
+		store := kubeclient.NewStore()
+		store.AddNode(&corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-1"}})
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default"}})
+		store.UpdateMetrics(metrics)
+		store.UpdateMetrics(metrics)
+
+		history, err := store.GetHistory(time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, "node-1", history[0].Nodes[0].Name)
+		assert.Equal(t, int64(100), history[0].Pods[0].CPUUsage)
+
+		store.KeepHistory(time.Hour, 0, 0)
+		store.UpdateMetrics(metrics)
+		history, err = store.GetHistory(time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(history))
+
+		history, err = store.GetHistory(time.Now().Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(history))
+	})
+
+	t.Run("History is thinned to its pod sample cap", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default"}})
+		store.KeepHistory(time.Hour, 0, 4)
+		for i := 0; i < 5; i++ {
+			store.UpdateMetrics(nil)
+		}
+
+		history, err := store.GetHistory(time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(history))
+		for i := 1; i < len(history); i++ {
+			assert.False(t, history[i].Time.Before(history[i-1].Time))
+		}
+	})
+
+	t.Run("History is disabled without retention", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		store.KeepHistory(0, 0, 0)
+		store.UpdateMetrics(nil)
+
+		history, err := store.GetHistory(time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(history))
+	})
+
+	t.Run("Container usage survives pod updates", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "web", Resources: corev1.ResourceRequirements{Requests: resources("100m", "64Mi")}},
+				{Name: "sidecar"},
+			}},
+		}
+		store := kubeclient.NewStore()
+		store.AddPod(pod)
+		store.UpdateMetrics([]v1beta1.PodMetrics{{
+			ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default"},
+			Containers: []v1beta1.ContainerMetrics{
+				{Name: "web", Usage: resources("80m", "40Mi")},
+				{Name: "sidecar", Usage: resources("5m", "8Mi")},
+			},
+		}})
+		before, _ := store.GetPods("")
+		store.ModifyPod(pod)
+
+		pods, err := store.GetPods("")
+		assert.Nil(t, err)
+		assert.Equal(t, []kubeclient.Container{
+			{Name: "web", CPURequest: 100, MemoryRequest: 64 << 20, CPUUsage: 80, MemoryUsage: 40 << 20},
+			{Name: "sidecar", CPUUsage: 5, MemoryUsage: 8 << 20},
+		}, pods[0].Containers)
+		assert.Equal(t, before[0].Containers, pods[0].Containers)
+	})
+
+	t.Run("QoS class is derived from the resources when not set", func(t *testing.T) {
+		store := kubeclient.NewStore()
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "best-effort"}, Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app"}},
+		}})
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "guaranteed"}, Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Limits: resources("1", "1Gi")}}},
+		}})
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "burstable"}, Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Requests: resources("1", "1Gi")}}},
+		}})
+		store.AddPod(&corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "reported"}, Status: corev1.PodStatus{QOSClass: corev1.PodQOSBurstable}})
+
+		pods, err := store.GetPods("")
+		assert.Nil(t, err)
+		assert.Equal(t, "BestEffort", pods[0].QOSClass)
+		assert.Equal(t, "Guaranteed", pods[1].QOSClass)
+		assert.Equal(t, "Burstable", pods[2].QOSClass)
+		assert.Equal(t, "Burstable", pods[3].QOSClass)
+	})
+
+	t.Run("Disruption budgets are added, updated and deleted", func(t *testing.T) {
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: v1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &v1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		}
+		store := kubeclient.NewStore()
+		store.SetDisruptionBudget(pdb)
+		pdb.Status.DisruptionsAllowed = 2
+		store.SetDisruptionBudget(pdb)
+
+		budgets, err := store.GetDisruptionBudgets()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(budgets))
+		assert.Equal(t, int32(2), budgets[0].DisruptionsAllowed)
+		assert.Equal(t, "web", budgets[0].Selector.MatchLabels["app"])
+
+		store.DeleteDisruptionBudget("shop", "web")
+		budgets, err = store.GetDisruptionBudgets()
+		assert.Nil(t, err)
+		assert.Empty(t, budgets)
+	})
+
+	t.Run("Pending pods keep their scheduling failure until scheduled", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "big", Namespace: "default"},
+			Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  "Unschedulable",
+				Message: "0/2 nodes are available: 2 Insufficient cpu.",
+			}}},
+		}
+		store := kubeclient.NewStore()
+		store.AddPod(pod)
+		store.AddEvent(kubeclient.Event{
+			UID:     "1",
+			Reason:  "FailedScheduling",
+			Message: "0/2 nodes are available: 2 Insufficient cpu.",
+			Object:  kubeclient.ObjectReference{Kind: "Pod", Namespace: "default", Name: "big"},
+			Last:    time.Now(),
+		})
+		store.ModifyPod(pod)
+
+		pods, err := store.GetPods("")
+		assert.Nil(t, err)
+		assert.Equal(t, "Unschedulable", pods[0].ScheduledReason)
+		assert.Equal(t, "0/2 nodes are available: 2 Insufficient cpu.", pods[0].ScheduledMessage)
+		assert.Equal(t, "0/2 nodes are available: 2 Insufficient cpu.", pods[0].SchedulingFailure)
+
+		pod.Spec.NodeName = "node1"
+		pod.Status.Conditions[0].Status = corev1.ConditionTrue
+		store.ModifyPod(pod)
+		pods, err = store.GetPods("")
+		assert.Nil(t, err)
+		assert.Empty(t, pods[0].ScheduledReason)
+		assert.Empty(t, pods[0].SchedulingFailure)
+	})
+
+	t.Run("Events are kept once per UID, bounded per object and pruned", func(t *testing.T) {
+		pod := kubeclient.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web"}
+		now := time.Now()
+		store := kubeclient.NewStore()
+		for i := 0; i < 25; i++ {
+			store.AddEvent(kubeclient.Event{UID: fmt.Sprint(i), Reason: "BackOff", Object: pod, Last: now.Add(time.Duration(i) * time.Second)})
+		}
+		store.AddEvent(kubeclient.Event{UID: "24", Reason: "BackOff", Count: 3, Object: pod, Last: now.Add(time.Minute)})
+		store.AddEvent(kubeclient.Event{UID: "old", Reason: "Pulled", Object: kubeclient.ObjectReference{Kind: "Pod", Name: "gone"}, Last: now.Add(-2 * time.Hour)})
+
+		events, err := store.GetEvents()
+		assert.Nil(t, err)
+		assert.Equal(t, 21, len(events))
+
+		store.PruneEvents()
+		events, err = store.GetEvents()
+		assert.Nil(t, err)
+		assert.Equal(t, 20, len(events))
+		assert.Equal(t, "24", events[0].UID)
+		assert.Equal(t, int32(3), events[0].Count)
+		assert.Equal(t, "5", events[19].UID)
+	})
+
+	t.Run("Container restarts and the last termination are recorded", func(t *testing.T) {
+		finished := v1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+		store := kubeclient.NewStore()
+		store.AddPod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web"}, {Name: "sidecar"}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "web",
+					RestartCount:         5,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137, FinishedAt: finished}},
+				},
+				{Name: "sidecar", RestartCount: 1, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}},
+		})
+
+		pods, err := store.GetPods("")
+		assert.Nil(t, err)
+		assert.Equal(t, int32(6), pods[0].Restarts)
+		web := pods[0].Containers[0]
+		assert.Equal(t, "Waiting", web.State)
+		assert.Equal(t, "CrashLoopBackOff", web.StateReason)
+		assert.Equal(t, "OOMKilled", web.LastTerminationReason)
+		assert.Equal(t, int32(137), web.LastExitCode)
+		assert.Equal(t, finished.Time, web.LastTerminated)
+		assert.Equal(t, "Running", pods[0].Containers[1].State)
+	})
 }
@@ -2,14 +2,32 @@ package kubeclient
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiwatch "k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
+const (
+	// volumeStatsInterval is how often the kubelets are asked for volume
+	// usage, which they compute about once a minute.
+	volumeStatsInterval = time.Minute
+	// volumeStatsTimeout bounds the wait for a single kubelet, so one
+	// unresponsive node does not hold back the others.
+	volumeStatsTimeout = 10 * time.Second
+	// volumeStatsConcurrency is how many kubelets are asked at once.
+	volumeStatsConcurrency = 8
+)
+
 type worker struct {
 	client  *kubernetes.Clientset
 	metrics *metricsv.Clientset
@@ -29,6 +47,83 @@ func (w *worker) Run(ctx context.Context) {
 	go w.watchNodes(ctx)
 	go w.watchPods(ctx)
 	go w.watchPodMetrics(ctx)
+	go w.watchDisruptionBudgets(ctx)
+	go w.watchEvents(ctx)
+	go w.pruneEvents(ctx)
+	go w.watchClaims(ctx)
+	go w.watchVolumes(ctx)
+	go w.watchStorageClasses(ctx)
+	go w.watchVolumeStats(ctx)
+}
+
+func (w *worker) Load(ctx context.Context) error {
+	namespaces, err := w.client.CoreV1().Namespaces().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces.Items {
+		w.store.AddNamespace(ns.Name)
+	}
+
+	nodes, err := w.client.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range nodes.Items {
+		w.store.AddNode(&nodes.Items[i])
+	}
+
+	pods, err := w.client.CoreV1().Pods("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		w.store.AddPod(&pods.Items[i])
+	}
+
+	pdbs, err := w.client.PolicyV1().PodDisruptionBudgets("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		w.store.SetError("pdbs", err)
+	} else {
+		for i := range pdbs.Items {
+			w.store.SetDisruptionBudget(&pdbs.Items[i])
+		}
+	}
+
+	// Storage is optional, like the budgets.
+	claims, err := w.client.CoreV1().PersistentVolumeClaims("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		w.store.SetError("pvcs", err)
+	} else {
+		for i := range claims.Items {
+			w.store.SetClaim(&claims.Items[i])
+		}
+	}
+	volumes, err := w.client.CoreV1().PersistentVolumes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		w.store.SetError("pvs", err)
+	} else {
+		for i := range volumes.Items {
+			w.store.SetVolume(&volumes.Items[i])
+		}
+	}
+	classes, err := w.client.StorageV1().StorageClasses().List(ctx, v1.ListOptions{})
+	if err != nil {
+		w.store.SetError("storageclasses", err)
+	} else {
+		for i := range classes.Items {
+			w.store.SetStorageClass(&classes.Items[i])
+		}
+	}
+
+	podMetrics, err := w.metrics.MetricsV1beta1().PodMetricses("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		// Without the metrics server the usage stays empty, like in the UI.
+		w.store.SetError("podMetrics", err)
+		return nil
+	}
+	w.store.UpdateMetrics(podMetrics.Items)
+	return nil
 }
 
 func (w *worker) watchNamespaces(ctx context.Context) {
@@ -76,6 +171,8 @@ func (w *worker) watchNodes(ctx context.Context) {
 				w.store.AddNode(node)
 			} else if event.Type == "DELETED" {
 				w.store.DeleteNode(node.Name)
+			} else if event.Type == "MODIFIED" {
+				w.store.ModifyNode(node)
 			}
 		}
 	}
@@ -100,7 +197,7 @@ func (w *worker) watchPods(ctx context.Context) {
 			if event.Type == "ADDED" {
 				w.store.AddPod(pod)
 			} else if event.Type == "DELETED" {
-				w.store.DeletePod(pod.Name)
+				w.store.DeletePod(pod.Namespace, pod.Name)
 			} else if event.Type == "MODIFIED" {
 				w.store.ModifyPod(pod)
 			}
@@ -108,6 +205,240 @@ func (w *worker) watchPods(ctx context.Context) {
 	}
 }
 
+func (w *worker) watchDisruptionBudgets(ctx context.Context) {
+	watch, err := w.client.PolicyV1().PodDisruptionBudgets("").Watch(ctx, v1.ListOptions{})
+	if err != nil {
+		w.store.SetError("pdbs", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-watch.ResultChan():
+			pdb, ok := event.Object.(*policyv1.PodDisruptionBudget)
+			if !ok {
+				continue
+			}
+			if event.Type == "DELETED" {
+				w.store.DeleteDisruptionBudget(pdb.Namespace, pdb.Name)
+			} else {
+				w.store.SetDisruptionBudget(pdb)
+			}
+		}
+	}
+}
+
+// watchEvents watches the events.k8s.io API, falling back to the core events
+// on clusters or roles that cannot watch it. The error is only kept when
+// neither can be watched.
+func (w *worker) watchEvents(ctx context.Context) {
+	for {
+		watch, err := w.client.EventsV1().Events("").Watch(ctx, v1.ListOptions{})
+		if err != nil {
+			w.watchCoreEvents(ctx, err)
+			return
+		}
+
+		closed := receive(ctx, watch, func(event apiwatch.Event) {
+			e, ok := event.Object.(*eventsv1.Event)
+			// Deleted events have expired, the store drops them on its own.
+			if !ok || event.Type == "DELETED" {
+				return
+			}
+			w.store.AddEvent(toEventV1(e))
+		})
+		if !closed {
+			return
+		}
+	}
+}
+
+func (w *worker) pruneEvents(ctx context.Context) {
+	ticker := time.NewTicker(eventPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.store.PruneEvents()
+		}
+	}
+}
+
+func (w *worker) watchCoreEvents(ctx context.Context, v1Err error) {
+	for {
+		watch, err := w.client.CoreV1().Events("").Watch(ctx, v1.ListOptions{})
+		if err != nil {
+			w.store.SetError("events", errors.Join(v1Err, err))
+			return
+		}
+
+		closed := receive(ctx, watch, func(event apiwatch.Event) {
+			e, ok := event.Object.(*corev1.Event)
+			if !ok || event.Type == "DELETED" {
+				return
+			}
+			w.store.AddEvent(toEvent(e))
+		})
+		if !closed {
+			return
+		}
+	}
+}
+
+// receive hands the events of the watch to handle until the API server
+// closes it, e.g. on timeout, and tells if it was closed rather than the
+// context done. Events and storage are kept by UID or name, so watching
+// again from scratch only replays what the store already has.
+func receive(ctx context.Context, watch apiwatch.Interface, handle func(apiwatch.Event)) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-watch.ResultChan():
+			if !ok {
+				return true
+			}
+			handle(event)
+		}
+	}
+}
+
+func (w *worker) watchClaims(ctx context.Context) {
+	for {
+		watch, err := w.client.CoreV1().PersistentVolumeClaims("").Watch(ctx, v1.ListOptions{})
+		if err != nil {
+			w.store.SetError("pvcs", err)
+			return
+		}
+
+		closed := receive(ctx, watch, func(event apiwatch.Event) {
+			claim, ok := event.Object.(*corev1.PersistentVolumeClaim)
+			if !ok {
+				return
+			}
+			if event.Type == "DELETED" {
+				w.store.DeleteClaim(claim.Namespace, claim.Name)
+			} else {
+				w.store.SetClaim(claim)
+			}
+		})
+		if !closed {
+			return
+		}
+	}
+}
+
+func (w *worker) watchVolumes(ctx context.Context) {
+	for {
+		watch, err := w.client.CoreV1().PersistentVolumes().Watch(ctx, v1.ListOptions{})
+		if err != nil {
+			w.store.SetError("pvs", err)
+			return
+		}
+
+		closed := receive(ctx, watch, func(event apiwatch.Event) {
+			volume, ok := event.Object.(*corev1.PersistentVolume)
+			if !ok {
+				return
+			}
+			if event.Type == "DELETED" {
+				w.store.DeleteVolume(volume.Name)
+			} else {
+				w.store.SetVolume(volume)
+			}
+		})
+		if !closed {
+			return
+		}
+	}
+}
+
+func (w *worker) watchStorageClasses(ctx context.Context) {
+	for {
+		watch, err := w.client.StorageV1().StorageClasses().Watch(ctx, v1.ListOptions{})
+		if err != nil {
+			w.store.SetError("storageclasses", err)
+			return
+		}
+
+		closed := receive(ctx, watch, func(event apiwatch.Event) {
+			class, ok := event.Object.(*storagev1.StorageClass)
+			if !ok {
+				return
+			}
+			if event.Type == "DELETED" {
+				w.store.DeleteStorageClass(class.Name)
+			} else {
+				w.store.SetStorageClass(class)
+			}
+		})
+		if !closed {
+			return
+		}
+	}
+}
+
+// watchVolumeStats reads the volume usage from the stats summary of the
+// kubelets running pods with claims, through the API server's node proxy.
+// Nodes whose stats cannot be read, e.g. without access to nodes/proxy, are
+// skipped and their errors kept.
+func (w *worker) watchVolumeStats(ctx context.Context) {
+	for {
+		var (
+			stats = make(map[ObjectReference]VolumeStats)
+			errs  []error
+			lock  sync.Mutex
+			wg    sync.WaitGroup
+			slots = make(chan struct{}, volumeStatsConcurrency)
+		)
+		for _, node := range w.store.claimNodes() {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(node string) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				nodeStats, err := w.nodeVolumeStats(ctx, node)
+				lock.Lock()
+				defer lock.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+				}
+				for key, value := range nodeStats {
+					stats[key] = value
+				}
+			}(node)
+		}
+		wg.Wait()
+		w.store.UpdateVolumeStats(stats)
+		w.store.SetError("volumeStats", errors.Join(errs...))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(volumeStatsInterval):
+		}
+	}
+}
+
+func (w *worker) nodeVolumeStats(ctx context.Context, node string) (map[ObjectReference]VolumeStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, volumeStatsTimeout)
+	defer cancel()
+
+	data, err := w.client.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", node, "proxy", "stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("volume stats of node %s: %w", node, err)
+	}
+	return ParseVolumeStats(data)
+}
+
 func (w *worker) watchPodMetrics(ctx context.Context) {
 	for {
 		podMetrics, err := w.metrics.MetricsV1beta1().PodMetricses("").List(ctx, v1.ListOptions{})
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Placement is where an evicted pod would go. Node is empty when the pod
+// would stay pending, with Reason saying why.
+type Placement struct {
+	Pod    kubeclient.Pod
+	Node   string
+	Reason string
+	// DisruptionBudget names the PodDisruptionBudget that would hold back the
+	// eviction until other pods it covers are ready again.
+	DisruptionBudget string
+}
+
+type DrainResult struct {
+	Node          string
+	Placements    []Placement
+	Unschedulable []Placement
+	// Ignored pods stay on the node: DaemonSet and static pods, and pods
+	// that already finished.
+	Ignored []Placement
+}
+
+// Drain simulates cordoning and draining the node: every pod that
+// `kubectl drain` would evict is scheduled again on the other nodes, higher
+// priority and larger pods first, like the scheduler queue would.
+func Drain(name string, nodes []kubeclient.Node, pods []kubeclient.Pod, budgets []kubeclient.DisruptionBudget) DrainResult {
+	result := DrainResult{Node: name}
+
+	podsByNode := Running(pods)
+	var states []*NodeState
+	for _, n := range nodes {
+		if n.Name != name {
+			states = append(states, NewNodeState(n, podsByNode[n.Name]))
+		}
+	}
+
+	var evicted []kubeclient.Pod
+	for _, p := range pods {
+		if p.Node != name {
+			continue
+		}
+		if ok, reason := Evictable(p); ok {
+			evicted = append(evicted, p)
+		} else {
+			result.Ignored = append(result.Ignored, Placement{Pod: p, Node: name, Reason: reason})
+		}
+	}
+	sort.SliceStable(evicted, func(i, j int) bool {
+		a, b := evicted[i], evicted[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.CPURequest != b.CPURequest {
+			return a.CPURequest > b.CPURequest
+		}
+		return a.MemoryRequest > b.MemoryRequest
+	})
+
+	allowed := make(map[string]int32)
+	for _, b := range budgets {
+		allowed[b.Namespace+"/"+b.Name] = b.DisruptionsAllowed
+	}
+	for _, p := range evicted {
+		placement := Placement{Pod: p}
+		if p.Workload == "" {
+			placement.Reason = "not managed by a controller, deleted without a replacement"
+			result.Unschedulable = append(result.Unschedulable, placement)
+			continue
+		}
+		for _, b := range budgets {
+			key := b.Namespace + "/" + b.Name
+			if covers(b, p) {
+				if allowed[key] <= 0 {
+					placement.DisruptionBudget = b.Name
+				}
+				allowed[key]--
+			}
+		}
+
+		node, reason := Schedule(p, states)
+		if node == nil {
+			placement.Reason = reason
+			result.Unschedulable = append(result.Unschedulable, placement)
+			continue
+		}
+		node.Add(p)
+		placement.Node = node.Name
+		result.Placements = append(result.Placements, placement)
+	}
+	return result
+}
+
+// Evictable reports whether draining the node of the pod would evict it, and
+// why not otherwise.
+func Evictable(p kubeclient.Pod) (bool, string) {
+	switch {
+	case Finished(p):
+		return false, "pod has finished"
+	case strings.HasPrefix(p.Workload, "DaemonSet/"):
+		return false, "DaemonSet pods are not evicted"
+	case p.Mirror:
+		return false, "static pods are not evicted"
+	default:
+		return true, ""
+	}
+}
+
+// Running returns the pods holding resources on the nodes.
+func Running(pods []kubeclient.Pod) map[string][]kubeclient.Pod {
+	podsByNode := make(map[string][]kubeclient.Pod)
+	for _, p := range pods {
+		if p.Node != "" && !Finished(p) {
+			podsByNode[p.Node] = append(podsByNode[p.Node], p)
+		}
+	}
+	return podsByNode
+}
+
+func covers(b kubeclient.DisruptionBudget, p kubeclient.Pod) bool {
+	if b.Namespace != p.Namespace || b.Selector == nil {
+		return false
+	}
+	selector, err := v1.LabelSelectorAsSelector(b.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(p.Labels))
+}
+
+// Finished reports whether the pod has succeeded or failed, which frees its
+// pod slot and the resources it requested.
+func Finished(p kubeclient.Pod) bool {
+	return p.Phase == "Succeeded" || p.Phase == "Failed"
+}
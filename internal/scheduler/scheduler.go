@@ -0,0 +1,295 @@
+// Package scheduler places pods on nodes the way the default kube-scheduler
+// would, to answer what-if questions without touching the cluster. It covers
+// resource requests, cordons, taints and tolerations, node selectors, required
+// node affinity and required pod anti-affinity on the hostname topology.
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const hostnameTopologyKey = "kubernetes.io/hostname"
+
+// NodeState is a node with the pods the simulation has placed on it.
+type NodeState struct {
+	kubeclient.Node
+	Pods          []kubeclient.Pod
+	CPURequest    int64
+	MemoryRequest int64
+	// EphemeralStorageRequest is the pods' requests of the node's local
+	// ephemeral storage.
+	EphemeralStorageRequest int64
+	// ExtendedRequests are the pods' requests of extended resources, like
+	// nvidia.com/gpu, by name.
+	ExtendedRequests map[string]int64
+}
+
+func NewNodeState(node kubeclient.Node, pods []kubeclient.Pod) *NodeState {
+	n := &NodeState{Node: node}
+	for _, p := range pods {
+		n.Add(p)
+	}
+	return n
+}
+
+func (n *NodeState) Add(p kubeclient.Pod) {
+	n.Pods = append(n.Pods, p)
+	n.CPURequest += p.CPURequest
+	n.MemoryRequest += p.MemoryRequest
+	n.EphemeralStorageRequest += p.EphemeralStorageRequest
+	for name, request := range p.ExtendedRequests {
+		if n.ExtendedRequests == nil {
+			n.ExtendedRequests = make(map[string]int64)
+		}
+		n.ExtendedRequests[name] += request
+	}
+}
+
+func (n *NodeState) Remove(namespace, name string) {
+	for i, p := range n.Pods {
+		if p.Namespace == namespace && p.Name == name {
+			n.Pods = append(n.Pods[:i:i], n.Pods[i+1:]...)
+			n.CPURequest -= p.CPURequest
+			n.MemoryRequest -= p.MemoryRequest
+			n.EphemeralStorageRequest -= p.EphemeralStorageRequest
+			for name, request := range p.ExtendedRequests {
+				n.ExtendedRequests[name] -= request
+			}
+			return
+		}
+	}
+}
+
+// Fits reports whether the pod can be scheduled on the node, and the reason,
+// worded like the scheduler's, when it cannot.
+func Fits(p kubeclient.Pod, n *NodeState) (bool, string) {
+	if n.Unschedulable && !tolerates(p.Tolerations, corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}) {
+		return false, "node(s) were unschedulable"
+	}
+	for _, taint := range n.Taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !tolerates(p.Tolerations, taint) {
+			return false, "node(s) had untolerated taint {" + taint.Key + ": " + taint.Value + "}"
+		}
+	}
+	for k, v := range p.NodeSelector {
+		if n.Labels[k] != v {
+			return false, "node(s) didn't match Pod's node affinity/selector"
+		}
+	}
+	if !matchesNodeAffinity(p.Affinity, n) {
+		return false, "node(s) didn't match Pod's node affinity/selector"
+	}
+	if conflictsWithAntiAffinity(p, n) {
+		return false, "node(s) didn't match pod anti-affinity rules"
+	}
+	if n.AllocatablePods > 0 && int64(len(n.Pods))+1 > n.AllocatablePods {
+		return false, "Too many pods"
+	}
+	if n.CPURequest+p.CPURequest > n.AvailableCPU {
+		return false, "Insufficient cpu"
+	}
+	if n.MemoryRequest+p.MemoryRequest > n.AllocatableMemory {
+		return false, "Insufficient memory"
+	}
+	if p.EphemeralStorageRequest > 0 && n.EphemeralStorageRequest+p.EphemeralStorageRequest > n.AllocatableEphemeralStorage {
+		return false, "Insufficient ephemeral-storage"
+	}
+	// Nodes without an extended resource have none of it allocatable.
+	names := make([]string, 0, len(p.ExtendedRequests))
+	for name := range p.ExtendedRequests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if request := p.ExtendedRequests[name]; request > 0 && n.ExtendedRequests[name]+request > n.ExtendedAllocatable[name] {
+			return false, "Insufficient " + name
+		}
+	}
+	return true, ""
+}
+
+// Schedule picks the node the pod would land on, preferring the least
+// allocated node and the first one in order on a tie. When no node fits it
+// returns nil and a summary of why, like a FailedScheduling event.
+func Schedule(p kubeclient.Pod, nodes []*NodeState) (*NodeState, string) {
+	var best *NodeState
+	bestScore := -1.0
+	reasons := make(map[string]int)
+	for _, n := range nodes {
+		ok, reason := Fits(p, n)
+		if !ok {
+			reasons[reason]++
+			continue
+		}
+		if score := leastAllocated(p, n); score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best != nil {
+		return best, ""
+	}
+	return nil, summarize(len(nodes), reasons)
+}
+
+// leastAllocated scores a node by the share of CPU and memory left once the
+// pod is placed, like the scheduler's NodeResourcesFit strategy.
+func leastAllocated(p kubeclient.Pod, n *NodeState) float64 {
+	free := func(requested, allocatable int64) float64 {
+		if allocatable == 0 {
+			return 0
+		}
+		return float64(allocatable-requested) / float64(allocatable)
+	}
+	return (free(n.CPURequest+p.CPURequest, n.AvailableCPU) + free(n.MemoryRequest+p.MemoryRequest, n.AllocatableMemory)) / 2
+}
+
+func summarize(total int, reasons map[string]int) string {
+	keys := make([]string, 0, len(reasons))
+	for reason := range reasons {
+		keys = append(keys, reason)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, reason := range keys {
+		parts = append(parts, fmt.Sprintf("%d %s", reasons[reason], reason))
+	}
+	summary := fmt.Sprintf("0/%d nodes are available", total)
+	if len(parts) > 0 {
+		summary += ": " + strings.Join(parts, ", ")
+	}
+	return summary + "."
+}
+
+func tolerates(tolerations []corev1.Toleration, taint corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(&taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeAffinity checks the required node affinity: any of the terms has
+// to match, with all of its requirements.
+func matchesNodeAffinity(affinity *corev1.Affinity, n *NodeState) bool {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for _, term := range terms {
+		if matchesTerm(term, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesTerm(term corev1.NodeSelectorTerm, n *NodeState) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, r := range term.MatchExpressions {
+		value, ok := n.Labels[r.Key]
+		if !matchesRequirement(r, value, ok) {
+			return false
+		}
+	}
+	for _, r := range term.MatchFields {
+		if r.Key != "metadata.name" || !matchesRequirement(r, n.Name, true) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesRequirement(r corev1.NodeSelectorRequirement, value string, ok bool) bool {
+	switch r.Operator {
+	case corev1.NodeSelectorOpIn:
+		return ok && contains(r.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !ok || !contains(r.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return ok
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !ok
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !ok || len(r.Values) != 1 {
+			return false
+		}
+		actual, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		bound, err := strconv.ParseInt(r.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if r.Operator == corev1.NodeSelectorOpGt {
+			return actual > bound
+		}
+		return actual < bound
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictsWithAntiAffinity checks required pod anti-affinity on the hostname
+// topology, both ways: the pod must not avoid the pods on the node, and they
+// must not avoid the pod.
+func conflictsWithAntiAffinity(p kubeclient.Pod, n *NodeState) bool {
+	for _, other := range n.Pods {
+		if avoids(p, other) || avoids(other, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// avoids reports whether p has a required anti-affinity term on the hostname
+// topology that matches other.
+func avoids(p, other kubeclient.Pod) bool {
+	if p.Affinity == nil || p.Affinity.PodAntiAffinity == nil {
+		return false
+	}
+	for _, term := range p.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey != hostnameTopologyKey {
+			continue
+		}
+		// Namespace labels are not in the store, so a namespace selector
+		// is taken to match every namespace.
+		namespaces := term.Namespaces
+		if len(namespaces) == 0 && term.NamespaceSelector == nil {
+			namespaces = []string{p.Namespace}
+		}
+		if term.NamespaceSelector == nil && !contains(namespaces, other.Namespace) {
+			continue
+		}
+		selector, err := v1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(other.Labels)) {
+			return true
+		}
+	}
+	return false
+}
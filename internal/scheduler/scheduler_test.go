@@ -0,0 +1,176 @@
+package scheduler_test
+
+import (
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name string, cpu, memory int64) kubeclient.Node {
+	return kubeclient.Node{Name: name, AvailableCPU: cpu, AllocatableMemory: memory, AllocatablePods: 110,
+		Labels: map[string]string{"kubernetes.io/hostname": name}}
+}
+
+func Test_Fits(t *testing.T) {
+	t.Run("given a cordoned node, then do not fit", func(t *testing.T) {
+		n := node("node1", 1000, 1<<30)
+		n.Unschedulable = true
+		ok, reason := scheduler.Fits(kubeclient.Pod{}, scheduler.NewNodeState(n, nil))
+		assert.False(t, ok)
+		assert.Equal(t, "node(s) were unschedulable", reason)
+	})
+
+	t.Run("given a taint, then fit only pods tolerating it", func(t *testing.T) {
+		n := node("node1", 1000, 1<<30)
+		n.Taints = []corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}}
+		state := scheduler.NewNodeState(n, nil)
+
+		ok, reason := scheduler.Fits(kubeclient.Pod{}, state)
+		assert.False(t, ok)
+		assert.Equal(t, "node(s) had untolerated taint {gpu: true}", reason)
+
+		ok, _ = scheduler.Fits(kubeclient.Pod{Tolerations: []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}}}, state)
+		assert.True(t, ok)
+	})
+
+	t.Run("given a node selector or node affinity, then match the node labels", func(t *testing.T) {
+		n := node("node1", 1000, 1<<30)
+		n.Labels["zone"] = "a"
+		state := scheduler.NewNodeState(n, nil)
+
+		ok, _ := scheduler.Fits(kubeclient.Pod{NodeSelector: map[string]string{"zone": "b"}}, state)
+		assert.False(t, ok)
+
+		affinity := func(op corev1.NodeSelectorOperator, values ...string) kubeclient.Pod {
+			return kubeclient.Pod{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: op, Values: values}}},
+				}},
+			}}}
+		}
+		ok, _ = scheduler.Fits(affinity(corev1.NodeSelectorOpIn, "a", "b"), state)
+		assert.True(t, ok)
+		ok, reason := scheduler.Fits(affinity(corev1.NodeSelectorOpNotIn, "a"), state)
+		assert.False(t, ok)
+		assert.Equal(t, "node(s) didn't match Pod's node affinity/selector", reason)
+	})
+
+	t.Run("given pod anti-affinity on the hostname, then keep replicas apart", func(t *testing.T) {
+		replica := kubeclient.Pod{Name: "web-1", Namespace: "shop", Labels: map[string]string{"app": "web"},
+			Affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &v1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					TopologyKey:   "kubernetes.io/hostname",
+				}},
+			}}}
+		state := scheduler.NewNodeState(node("node1", 1000, 1<<30), []kubeclient.Pod{replica})
+
+		other := replica
+		other.Name = "web-2"
+		ok, reason := scheduler.Fits(other, state)
+		assert.False(t, ok)
+		assert.Equal(t, "node(s) didn't match pod anti-affinity rules", reason)
+
+		ok, _ = scheduler.Fits(kubeclient.Pod{Namespace: "other", Labels: map[string]string{"app": "web"}}, state)
+		assert.True(t, ok)
+	})
+
+	t.Run("given requests above the free resources, then do not fit", func(t *testing.T) {
+		state := scheduler.NewNodeState(node("node1", 1000, 1<<30), []kubeclient.Pod{{CPURequest: 800}})
+		ok, reason := scheduler.Fits(kubeclient.Pod{CPURequest: 300}, state)
+		assert.False(t, ok)
+		assert.Equal(t, "Insufficient cpu", reason)
+	})
+}
+
+func Test_Schedule(t *testing.T) {
+	t.Run("given extended resource requests, then fit only nodes with enough of them free", func(t *testing.T) {
+		gpu := func(n int64) map[string]int64 { return map[string]int64{"nvidia.com/gpu": n} }
+		train := kubeclient.Pod{Name: "train", ExtendedRequests: gpu(2)}
+
+		ok, reason := scheduler.Fits(train, scheduler.NewNodeState(node("cpu-1", 4000, 8<<30), nil))
+		assert.False(t, ok)
+		assert.Equal(t, "Insufficient nvidia.com/gpu", reason)
+
+		n := node("gpu-1", 4000, 8<<30)
+		n.ExtendedAllocatable = gpu(4)
+		state := scheduler.NewNodeState(n, []kubeclient.Pod{{Name: "serve", ExtendedRequests: gpu(3)}})
+		ok, _ = scheduler.Fits(train, state)
+		assert.False(t, ok)
+
+		state.Remove("", "serve")
+		ok, _ = scheduler.Fits(train, state)
+		assert.True(t, ok)
+	})
+
+	t.Run("given ephemeral storage requests, then fit only nodes with enough of it free", func(t *testing.T) {
+		n := node("node1", 4000, 8<<30)
+		n.AllocatableEphemeralStorage = 20 << 30
+		state := scheduler.NewNodeState(n, []kubeclient.Pod{{Name: "build-1", EphemeralStorageRequest: 15 << 30}})
+
+		ok, reason := scheduler.Fits(kubeclient.Pod{Name: "build-2", EphemeralStorageRequest: 10 << 30}, state)
+		assert.False(t, ok)
+		assert.Equal(t, "Insufficient ephemeral-storage", reason)
+
+		ok, _ = scheduler.Fits(kubeclient.Pod{Name: "web"}, state)
+		assert.True(t, ok)
+	})
+
+	t.Run("given several nodes, then pick the least allocated", func(t *testing.T) {
+		busy := scheduler.NewNodeState(node("busy", 1000, 1<<30), []kubeclient.Pod{{CPURequest: 500}})
+		idle := scheduler.NewNodeState(node("idle", 1000, 1<<30), nil)
+		n, _ := scheduler.Schedule(kubeclient.Pod{CPURequest: 100}, []*scheduler.NodeState{busy, idle})
+		assert.Equal(t, "idle", n.Name)
+	})
+
+	t.Run("given no node fits, then summarize why", func(t *testing.T) {
+		cordoned := node("cordoned", 1000, 1<<30)
+		cordoned.Unschedulable = true
+		nodes := []*scheduler.NodeState{
+			scheduler.NewNodeState(cordoned, nil),
+			scheduler.NewNodeState(node("small1", 100, 1<<30), nil),
+			scheduler.NewNodeState(node("small2", 100, 1<<30), nil),
+		}
+		n, reason := scheduler.Schedule(kubeclient.Pod{CPURequest: 500}, nodes)
+		assert.Nil(t, n)
+		assert.Equal(t, "0/3 nodes are available: 2 Insufficient cpu, 1 node(s) were unschedulable.", reason)
+	})
+}
+
+func Test_Drain(t *testing.T) {
+	nodes := []kubeclient.Node{node("node1", 2000, 4<<30), node("node2", 2000, 4<<30), node("node3", 1000, 2<<30)}
+	pods := []kubeclient.Pod{
+		{Name: "web-1", Namespace: "shop", Node: "node1", Workload: "Deployment/web", CPURequest: 500, Labels: map[string]string{"app": "web"}},
+		{Name: "web-2", Namespace: "shop", Node: "node1", Workload: "Deployment/web", CPURequest: 400, Labels: map[string]string{"app": "web"}},
+		{Name: "big", Namespace: "shop", Node: "node1", Workload: "StatefulSet/big", CPURequest: 1800},
+		{Name: "logs", Namespace: "kube-system", Node: "node1", Workload: "DaemonSet/logs", CPURequest: 100},
+		{Name: "debug", Namespace: "shop", Node: "node1"},
+		{Name: "job", Namespace: "shop", Node: "node1", Workload: "Job/job", Phase: "Succeeded", CPURequest: 1000},
+		{Name: "db", Namespace: "shop", Node: "node2", Workload: "StatefulSet/db", CPURequest: 1000},
+	}
+	budgets := []kubeclient.DisruptionBudget{{Name: "web", Namespace: "shop", DisruptionsAllowed: 1,
+		Selector: &v1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}}
+
+	result := scheduler.Drain("node1", nodes, pods, budgets)
+
+	placed := make(map[string]scheduler.Placement)
+	for _, p := range result.Placements {
+		placed[p.Pod.Name] = p
+	}
+	assert.Equal(t, 2, len(result.Placements))
+	assert.Equal(t, "node3", placed["web-1"].Node)
+	assert.Equal(t, "node2", placed["web-2"].Node)
+	assert.Equal(t, "", placed["web-1"].DisruptionBudget)
+	assert.Equal(t, "web", placed["web-2"].DisruptionBudget)
+
+	assert.Equal(t, 2, len(result.Unschedulable))
+	assert.Equal(t, "big", result.Unschedulable[0].Pod.Name)
+	assert.Equal(t, "0/2 nodes are available: 2 Insufficient cpu.", result.Unschedulable[0].Reason)
+	assert.Equal(t, "debug", result.Unschedulable[1].Pod.Name)
+
+	assert.Equal(t, []string{"logs", "job"}, []string{result.Ignored[0].Pod.Name, result.Ignored[1].Pod.Name})
+}
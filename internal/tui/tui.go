@@ -0,0 +1,407 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jawahars16/hawk8s/internal/core"
+)
+
+const (
+	sidebarWidth = 24
+	rowsPerNode  = 3
+)
+
+var (
+	barColor     = tcell.GetColor("#E2E8F0") // Slate 200, like the node bars in core.html
+	headerStyle  = tcell.StyleDefault.Reverse(true)
+	selectedMark = tcell.StyleDefault.Bold(true)
+	errorStyle   = tcell.StyleDefault.Foreground(tcell.ColorRed)
+)
+
+type (
+	// node, pod and namespace are what the TUI draws of the view models of
+	// core.Service.
+	node struct {
+		Name string
+		Info string
+	}
+	pod struct {
+		Name        string
+		Namespace   string
+		Status      string
+		Color       string
+		CpuShare    float32
+		MemoryShare float32
+		CpuUsage    string
+		MemoryUsage string
+	}
+	namespace struct {
+		Name  string
+		Color string
+	}
+)
+
+// TUI renders the nodes view of core.html in a terminal: a bar per node with
+// a slice per pod, colored by namespace.
+type TUI struct {
+	service         *core.Service
+	screen          tcell.Screen
+	activeMode      string
+	activeNamespace string
+	nodes           []node
+	pods            map[string][]pod
+	namespaces      []namespace
+	selected        int
+	offset          int
+	showPods        bool
+	err             error
+}
+
+func NewTUI(service *core.Service, screen tcell.Screen, mode string, namespace string) *TUI {
+	if mode != core.Memory {
+		mode = core.CPU
+	}
+	if namespace == "" {
+		namespace = "all"
+	}
+	return &TUI{
+		service:         service,
+		screen:          screen,
+		activeMode:      mode,
+		activeNamespace: namespace,
+		pods:            make(map[string][]pod),
+	}
+}
+
+// Run draws the view until the user quits or ctx is done. The caller owns the
+// screen and is expected to have initialized it.
+func (t *TUI) Run(ctx context.Context) error {
+	// The poller stops once the screen is finalized, or hands its last event
+	// to nobody after Run returned.
+	events := make(chan tcell.Event)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			ev := t.screen.PollEvent()
+			if ev == nil {
+				return
+			}
+			select {
+			case events <- ev:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	t.refresh(ctx)
+	t.draw()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			t.refresh(ctx)
+		case ev := <-events:
+			switch ev := ev.(type) {
+			case *tcell.EventKey:
+				if !t.handleKey(ctx, ev) {
+					return nil
+				}
+			case *tcell.EventResize:
+				t.screen.Sync()
+			}
+		}
+		t.draw()
+	}
+}
+
+func (t *TUI) refresh(ctx context.Context) {
+	t.err = nil
+	nodes, err := t.service.GetNodes(ctx)
+	if err != nil {
+		t.err = err
+		return
+	}
+	t.nodes = t.nodes[:0]
+	for _, n := range nodes {
+		t.nodes = append(t.nodes, node{Name: n.Name, Info: n.Info})
+	}
+	if t.selected >= len(t.nodes) {
+		t.selected = len(t.nodes) - 1
+	}
+	if t.selected < 0 {
+		t.selected = 0
+	}
+
+	for _, n := range nodes {
+		pods, err := t.service.GetPods(ctx, n.Name)
+		if err != nil {
+			t.err = err
+		}
+		t.pods[n.Name] = t.pods[n.Name][:0]
+		for _, p := range pods {
+			t.pods[n.Name] = append(t.pods[n.Name], pod{
+				Name:        p.Name,
+				Namespace:   p.Namespace,
+				Status:      p.Status,
+				Color:       p.Color,
+				CpuShare:    p.CpuShare,
+				MemoryShare: p.MemoryShare,
+				CpuUsage:    p.CpuUsage,
+				MemoryUsage: p.MemoryUsage,
+			})
+		}
+	}
+
+	namespaces, err := t.service.GetNamespaces(ctx)
+	if err != nil {
+		t.err = err
+		return
+	}
+	t.namespaces = t.namespaces[:0]
+	for _, ns := range namespaces {
+		t.namespaces = append(t.namespaces, namespace{Name: ns.Name, Color: ns.Color})
+	}
+}
+
+// handleKey applies a key press and returns false when the user quits.
+func (t *TUI) handleKey(ctx context.Context, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return false
+	case tcell.KeyEscape:
+		if !t.showPods {
+			return false
+		}
+		t.showPods = false
+	case tcell.KeyUp:
+		t.selected--
+	case tcell.KeyDown:
+		t.selected++
+	case tcell.KeyEnter:
+		t.showPods = !t.showPods
+	case tcell.KeyTab:
+		t.toggleMode()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return false
+		case 'k':
+			t.selected--
+		case 'j':
+			t.selected++
+		case 'c':
+			t.activeMode = core.CPU
+		case 'm':
+			t.activeMode = core.Memory
+		case 'n':
+			t.cycleNamespace(1)
+		case 'p':
+			t.cycleNamespace(-1)
+		case 'a':
+			t.activeNamespace = "all"
+		case 'r':
+			t.refresh(ctx)
+		}
+	}
+
+	if t.selected >= len(t.nodes) {
+		t.selected = len(t.nodes) - 1
+	}
+	if t.selected < 0 {
+		t.selected = 0
+	}
+	return true
+}
+
+func (t *TUI) toggleMode() {
+	if t.activeMode == core.CPU {
+		t.activeMode = core.Memory
+	} else {
+		t.activeMode = core.CPU
+	}
+}
+
+// cycleNamespace moves the active namespace through "all" and the namespaces
+// listed in the sidebar.
+func (t *TUI) cycleNamespace(step int) {
+	names := []string{"all"}
+	for _, ns := range t.namespaces {
+		names = append(names, ns.Name)
+	}
+	current := 0
+	for i, name := range names {
+		if name == t.activeNamespace {
+			current = i
+		}
+	}
+	t.activeNamespace = names[(current+step+len(names))%len(names)]
+}
+
+func (t *TUI) draw() {
+	t.screen.Clear()
+	width, height := t.screen.Size()
+
+	modeName := "CPU"
+	if t.activeMode == core.Memory {
+		modeName = "Memory"
+	}
+	t.fill(0, 0, width, headerStyle)
+	t.text(1, 0, width, headerStyle, fmt.Sprintf("hawk8s | Mode: %s | Namespace: %s", modeName, t.activeNamespace))
+
+	t.drawSidebar(height)
+	if t.showPods {
+		t.drawPods(sidebarWidth+1, width, height)
+	} else {
+		t.drawNodes(sidebarWidth+1, width, height)
+	}
+
+	footer := "↑/↓ select  enter pods  tab mode  n/p namespace  a all  r refresh  q quit"
+	if t.err != nil {
+		t.text(1, height-1, width, errorStyle, t.err.Error())
+	} else {
+		t.text(1, height-1, width, tcell.StyleDefault.Dim(true), footer)
+	}
+	t.screen.Show()
+}
+
+func (t *TUI) drawSidebar(height int) {
+	bold := tcell.StyleDefault.Bold(true)
+	t.text(1, 2, sidebarWidth, bold, "Mode")
+	for i, m := range []string{core.CPU, core.Memory} {
+		style := tcell.StyleDefault
+		if m == t.activeMode {
+			style = style.Reverse(true)
+		}
+		name := "CPU"
+		if m == core.Memory {
+			name = "Memory"
+		}
+		t.text(1, 3+i, sidebarWidth, style, " "+name+" ")
+	}
+
+	t.text(1, 6, sidebarWidth, bold, "Namespace")
+	entries := append([]namespace{{Name: "all", Color: "#000000"}}, t.namespaces...)
+	for i, ns := range entries {
+		y := 7 + i
+		if y >= height-1 {
+			break
+		}
+		style := tcell.StyleDefault
+		if ns.Name == t.activeNamespace {
+			style = style.Reverse(true)
+		}
+		label := ns.Name
+		if i == 0 {
+			label = "All"
+		}
+		t.screen.SetContent(1, y, '■', nil, tcell.StyleDefault.Foreground(tcell.GetColor(ns.Color)))
+		t.text(3, y, sidebarWidth, style, label)
+	}
+}
+
+func (t *TUI) drawNodes(x, width, height int) {
+	if len(t.nodes) == 0 {
+		t.text(x, 2, width, tcell.StyleDefault, "No nodes")
+		return
+	}
+
+	visible := (height - 3) / rowsPerNode
+	if visible < 1 {
+		visible = 1
+	}
+	if t.selected < t.offset {
+		t.offset = t.selected
+	}
+	if t.selected >= t.offset+visible {
+		t.offset = t.selected - visible + 1
+	}
+
+	for i := t.offset; i < len(t.nodes) && i < t.offset+visible; i++ {
+		n := t.nodes[i]
+		y := 2 + (i-t.offset)*rowsPerNode
+		style := tcell.StyleDefault
+		if i == t.selected {
+			style = selectedMark.Reverse(true)
+		}
+		t.text(x, y, width, style, fmt.Sprintf("%s | %s", n.Name, n.Info))
+		t.drawBar(x, y+1, width-x-1, t.pods[n.Name])
+	}
+}
+
+// drawBar draws the pods of a node as slices of a bar, sized by their share
+// of the node's allocatable CPU or memory.
+func (t *TUI) drawBar(x, y, width int, pods []pod) {
+	t.fill(x, y, x+width, tcell.StyleDefault.Background(barColor))
+
+	var used float64
+	for _, p := range pods {
+		share := float64(p.CpuShare)
+		if t.activeMode == core.Memory {
+			share = float64(p.MemoryShare)
+		}
+		start := int(math.Round(used * float64(width) / 100))
+		used += share
+		end := int(math.Round(used * float64(width) / 100))
+		if end <= start {
+			end = start + 1
+		}
+		if end > width {
+			end = width
+		}
+
+		color := tcell.GetColor(p.Color)
+		if t.activeNamespace != "all" && t.activeNamespace != p.Namespace {
+			color = tcell.GetColor(core.GrayHex(p.Color))
+		}
+		t.fill(x+start, y, x+end, tcell.StyleDefault.Background(color))
+	}
+}
+
+func (t *TUI) drawPods(x, width, height int) {
+	if len(t.nodes) == 0 {
+		return
+	}
+	n := t.nodes[t.selected]
+	t.text(x, 2, width, selectedMark, fmt.Sprintf("%s | %s", n.Name, n.Info))
+
+	row := "%-48s %-20s %-10s %8s %8s"
+	t.text(x, 4, width, tcell.StyleDefault.Bold(true), fmt.Sprintf(row, "POD", "NAMESPACE", "STATUS", "CPU", "MEMORY"))
+	y := 5
+	for _, p := range t.pods[n.Name] {
+		if y >= height-1 {
+			break
+		}
+		if t.activeNamespace != "all" && t.activeNamespace != p.Namespace {
+			continue
+		}
+		t.screen.SetContent(x, y, '■', nil, tcell.StyleDefault.Foreground(tcell.GetColor(p.Color)))
+		t.text(x+2, y, width, tcell.StyleDefault, fmt.Sprintf(row, p.Name, p.Namespace, p.Status, p.CpuUsage, p.MemoryUsage))
+		y++
+	}
+}
+
+func (t *TUI) text(x, y, maxX int, style tcell.Style, s string) {
+	for _, r := range s {
+		if x >= maxX {
+			return
+		}
+		t.screen.SetContent(x, y, r, nil, style)
+		x++
+	}
+}
+
+func (t *TUI) fill(x, y, maxX int, style tcell.Style) {
+	for ; x < maxX; x++ {
+		t.screen.SetContent(x, y, ' ', nil, style)
+	}
+}
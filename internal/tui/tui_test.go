@@ -0,0 +1,88 @@
+package tui_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jawahars16/hawk8s/internal/core"
+	"github.com/jawahars16/hawk8s/internal/kubeclient"
+	"github.com/jawahars16/hawk8s/internal/tui"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTUIScreen(t *testing.T) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	assert.Nil(t, screen.Init())
+	screen.SetSize(100, 20)
+	t.Cleanup(screen.Fini)
+	return screen
+}
+
+func screenLine(screen tcell.SimulationScreen, y int) string {
+	cells, width, _ := screen.GetContents()
+	var line strings.Builder
+	for x := 0; x < width; x++ {
+		for _, r := range cells[y*width+x].Runes {
+			line.WriteRune(r)
+		}
+	}
+	return line.String()
+}
+
+func Test_TUI(t *testing.T) {
+	kube := &core.KubeMock{
+		GetNamespacesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"team-a", "team-b"}, nil
+		},
+		GetNodesFunc: func(ctx context.Context) ([]kubeclient.Node, error) {
+			return []kubeclient.Node{{Name: "node1", AvailableCPU: 1000, AllocatableMemory: 1000}}, nil
+		},
+		GetPodsFunc: func(ctx context.Context, node string) ([]kubeclient.Pod, error) {
+			return []kubeclient.Pod{
+				{Name: "pod-a", Namespace: "team-a", Node: "node1", CPUUsage: 500, MemoryUsage: 100},
+				{Name: "pod-b", Namespace: "team-b", Node: "node1", CPUUsage: 250, MemoryUsage: 100},
+			}, nil
+		},
+		GetNodeFunc: func(ctx context.Context, name string) (kubeclient.Node, error) {
+			return kubeclient.Node{Name: name, AvailableCPU: 1000, AllocatableMemory: 1000}, nil
+		},
+		GetEventsFunc: func(ctx context.Context) ([]kubeclient.Event, error) {
+			return nil, nil
+		},
+	}
+
+	t.Run("given cpu mode, then size pod slices by cpu share", func(t *testing.T) {
+		screen := newTUIScreen(t)
+		screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+		assert.Nil(t, tui.NewTUI(core.NewService(kube), screen, core.CPU, "").Run(context.Background()))
+
+		assert.Contains(t, screenLine(screen, 0), "Mode: CPU")
+		assert.Contains(t, screenLine(screen, 2), "node1")
+
+		cells, width, _ := screen.GetContents()
+		barStart, barWidth := 25, 100-25-1
+		_, podA, _ := cells[3*width+barStart].Style.Decompose()
+		_, podB, _ := cells[3*width+barStart+barWidth/2].Style.Decompose()
+		_, free, _ := cells[3*width+barStart+barWidth*3/4+1].Style.Decompose()
+		assert.NotEqual(t, tcell.GetColor("#E2E8F0"), podA)
+		assert.NotEqual(t, tcell.GetColor("#E2E8F0"), podB)
+		assert.NotEqual(t, podA, podB)
+		assert.Equal(t, tcell.GetColor("#E2E8F0"), free)
+	})
+
+	t.Run("given a namespace filter, then gray out other namespaces", func(t *testing.T) {
+		screen := newTUIScreen(t)
+		screen.InjectKey(tcell.KeyRune, 'n', tcell.ModNone)
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+		assert.Nil(t, tui.NewTUI(core.NewService(kube), screen, core.CPU, "").Run(context.Background()))
+
+		assert.Contains(t, screenLine(screen, 0), "Mode: Memory | Namespace: team-a")
+		cells, width, _ := screen.GetContents()
+		_, podB, _ := cells[3*width+25+8].Style.Decompose()
+		r, g, b := podB.RGB()
+		assert.True(t, r == g && g == b, "team-b slice is gray")
+	})
+}
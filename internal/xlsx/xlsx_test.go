@@ -0,0 +1,50 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/jawahars16/hawk8s/internal/xlsx"
+	"github.com/stretchr/testify/assert"
+)
+
+func readFile(t *testing.T, data []byte, name string) string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.Nil(t, err)
+	f, err := r.Open(name)
+	assert.Nil(t, err)
+	content, err := io.ReadAll(f)
+	assert.Nil(t, err)
+	return string(content)
+}
+
+func Test_Write(t *testing.T) {
+	t.Run("given rows, then write numbers and escaped strings", func(t *testing.T) {
+		var out bytes.Buffer
+		err := xlsx.Write(&out, "Usage", [][]interface{}{
+			{"pod", "cpu"},
+			{"a<b>&c", int64(250)},
+		})
+		assert.Nil(t, err)
+
+		sheet := readFile(t, out.Bytes(), "xl/worksheets/sheet1.xml")
+		assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t>pod</t></is></c>`)
+		assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t>a&lt;b&gt;&amp;c</t></is></c>`)
+		assert.Contains(t, sheet, `<c r="B2"><v>250</v></c>`)
+		assert.Contains(t, readFile(t, out.Bytes(), "xl/workbook.xml"), `<sheet name="Usage"`)
+	})
+
+	t.Run("given more than 26 columns, then name them like spreadsheets do", func(t *testing.T) {
+		row := make([]interface{}, 28)
+		for i := range row {
+			row[i] = i
+		}
+		var out bytes.Buffer
+		assert.Nil(t, xlsx.Write(&out, "Sheet1", [][]interface{}{row}))
+		sheet := readFile(t, out.Bytes(), "xl/worksheets/sheet1.xml")
+		assert.Contains(t, sheet, `<c r="Z1"><v>25</v></c>`)
+		assert.Contains(t, sheet, `<c r="AB1"><v>27</v></c>`)
+	})
+}